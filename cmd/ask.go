@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var askLimit int
+
+var askCmd = &cobra.Command{
+	Use:   "ask <question>",
+	Short: "Finds the explanations that answer a question",
+	Long: `This command matches a free-form question against every topic and recipe.
+Matching runs fully offline, ranking topics by TF-IDF similarity after
+stemming words and folding synonyms together.
+For example:
+
+- explain ask "how do I see who changed this line"
+- explain ask "throw away my local edits"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println(`Please provide a question, for example:
+explain ask "how do I see who changed this line"`)
+			return
+		}
+
+		if askLimit < 1 {
+			fmt.Printf("--limit must be at least 1, got %d.\n", askLimit)
+			return
+		}
+
+		question := strings.Join(args, " ")
+		matches := newSearchIndex(searchDocuments()).search(question)
+		if len(matches) == 0 {
+			fmt.Printf("No topic matches '%s'. Try rephrasing the question.\n", question)
+			return
+		}
+		if len(matches) > askLimit {
+			matches = matches[:askLimit]
+		}
+
+		fmt.Printf("Best matches for '%s':\n", question)
+		for _, m := range matches {
+			fmt.Printf("%3.0f%%  %-36s %s\n", m.score*100, m.doc.command, m.doc.title)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(askCmd)
+
+	askCmd.Flags().IntVarP(&askLimit, "limit", "n", 5, "Maximum number of matches to show")
+}
+
+// searchDocument is a topic or recipe as seen by the ranking model.
+type searchDocument struct {
+	command string // what to run to read the explanation
+	title   string
+	text    string
+}
+
+func searchDocuments() []searchDocument {
+	var docs []searchDocument
+	for _, t := range topics {
+		docs = append(docs, searchDocument{
+			command: t.ref.String(),
+			title:   t.summary,
+			text:    t.ref.tool + " " + t.ref.name + " " + t.summary,
+		})
+	}
+	for _, r := range recipes {
+		text := r.goal + " " + strings.Join(r.keywords, " ")
+		for _, s := range r.steps {
+			text += " " + s.command + " " + s.explanation
+		}
+		docs = append(docs, searchDocument{
+			command: "explain how " + r.id,
+			title:   r.goal,
+			text:    text,
+		})
+	}
+	return docs
+}
+
+type searchMatch struct {
+	doc   searchDocument
+	score float64
+}
+
+// searchIndex ranks documents against a query using TF-IDF weighted cosine
+// similarity.
+type searchIndex struct {
+	docs    []searchDocument
+	vectors []map[string]float64
+	idf     map[string]float64
+}
+
+func newSearchIndex(docs []searchDocument) *searchIndex {
+	idx := &searchIndex{docs: docs, idf: map[string]float64{}}
+
+	counts := make([]map[string]float64, len(docs))
+	df := map[string]int{}
+	for i, d := range docs {
+		counts[i] = termCounts(d.text)
+		for term := range counts[i] {
+			df[term]++
+		}
+	}
+	for term, n := range df {
+		idx.idf[term] = math.Log(float64(len(docs)+1)/float64(n+1)) + 1
+	}
+	for _, c := range counts {
+		idx.vectors = append(idx.vectors, idx.weigh(c))
+	}
+	return idx
+}
+
+// weigh turns raw term counts into a unit-length TF-IDF vector. Terms that
+// never occur in the corpus are dropped since they cannot match anything.
+func (idx *searchIndex) weigh(counts map[string]float64) map[string]float64 {
+	vector := map[string]float64{}
+	var norm float64
+	for term, n := range counts {
+		idf, ok := idx.idf[term]
+		if !ok {
+			continue
+		}
+		w := (1 + math.Log(n)) * idf
+		vector[term] = w
+		norm += w * w
+	}
+	norm = math.Sqrt(norm)
+	for term := range vector {
+		vector[term] /= norm
+	}
+	return vector
+}
+
+func (idx *searchIndex) search(query string) []searchMatch {
+	q := idx.weigh(termCounts(query))
+
+	var matches []searchMatch
+	for i, v := range idx.vectors {
+		var score float64
+		for term, w := range q {
+			score += w * v[term]
+		}
+		if score > 0 {
+			matches = append(matches, searchMatch{doc: idx.docs[i], score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	return matches
+}
+
+func termCounts(text string) map[string]float64 {
+	counts := map[string]float64{}
+	for _, term := range tokenize(text) {
+		counts[term]++
+	}
+	return counts
+}
+
+// tokenize splits text into lowercase words, drops stop words, folds
+// synonyms and reduces each word to its stem.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var terms []string
+	for _, w := range words {
+		if stopWords[w] || len(w) < 2 {
+			continue
+		}
+		if s, ok := synonyms[w]; ok {
+			w = s
+		}
+		terms = append(terms, stem(w))
+	}
+	return terms
+}
+
+// stem strips common English suffixes. It is deliberately simple; it only
+// needs to map words like "changed", "changes" and "changing" together.
+func stem(word string) string {
+	for _, suffix := range []string{"ational", "ization", "ingly", "ness", "ment", "ing", "ies", "ied", "edly", "ed", "es", "ly", "s"} {
+		if !strings.HasSuffix(word, suffix) || len(word)-len(suffix) < 3 {
+			continue
+		}
+		word = strings.TrimSuffix(word, suffix)
+		if suffix == "ies" || suffix == "ied" {
+			word += "y"
+		}
+		break
+	}
+	return strings.TrimSuffix(word, "e")
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "can": true, "do": true, "does": true, "for": true, "from": true,
+	"how": true, "i": true, "in": true, "into": true, "is": true, "it": true, "me": true,
+	"my": true, "of": true, "on": true, "or": true, "so": true, "that": true, "the": true,
+	"them": true, "then": true, "there": true, "this": true, "to": true, "want": true,
+	"was": true, "what": true, "when": true, "where": true, "which": true, "who": true,
+	"why": true, "will": true, "with": true, "without": true, "you": true, "your": true,
+}
+
+// synonyms folds words people use in questions onto the vocabulary used by
+// the topics.
+var synonyms = map[string]string{
+	"delete":      "remove",
+	"erase":       "remove",
+	"drop":        "remove",
+	"discard":     "remove",
+	"throw":       "remove",
+	"rid":         "remove",
+	"purge":       "remove",
+	"cancel":      "undo",
+	"rollback":    "undo",
+	"reverse":     "undo",
+	"restore":     "recover",
+	"rescue":      "recover",
+	"retrieve":    "recover",
+	"lost":        "recover",
+	"edit":        "change",
+	"edits":       "change",
+	"modify":      "change",
+	"modified":    "change",
+	"alter":       "change",
+	"blame":       "author",
+	"authored":    "author",
+	"wrote":       "author",
+	"commits":     "commit",
+	"upload":      "push",
+	"publish":     "push",
+	"send":        "push",
+	"download":    "fetch",
+	"get":         "fetch",
+	"grab":        "fetch",
+	"duplicate":   "copy",
+	"combine":     "merge",
+	"integrate":   "merge",
+	"join":        "merge",
+	"squash":      "rebase",
+	"make":        "create",
+	"start":       "create",
+	"new":         "create",
+	"list":        "show",
+	"see":         "show",
+	"view":        "show",
+	"display":     "show",
+	"check":       "show",
+	"inspect":     "show",
+	"shelve":      "stash",
+	"park":        "stash",
+	"temporarily": "temporary",
+	"regression":  "bug",
+	"broke":       "bug",
+	"broken":      "bug",
+	"release":     "tag",
+	"version":     "tag",
+	"containers":  "container",
+	"storage":     "volume",
+	"disk":        "volume",
+	"data":        "volume",
+	"talk":        "network",
+	"communicate": "network",
+	"connect":     "network",
+	"port":        "network",
+	"cluster":     "swarm",
+	"orchestrate": "swarm",
+	"stack":       "compose",
+	"services":    "compose",
+	"multi":       "compose",
+	"dockerfile":  "build",
+	"registry":    "push",
+	"repo":        "repository",
+	"directory":   "folder",
+	"kubernetes":  "kubectl",
+	"k8s":         "kubectl",
+	"pods":        "pod",
+	"deployments": "deployment",
+}
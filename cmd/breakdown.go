@@ -0,0 +1,408 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// flagDescriptions explains the common flags of each topic, keyed by
+// "tool topic". Short and long spellings are listed separately.
+var flagDescriptions = map[string]map[string]string{
+	"git init": {
+		"--bare":           "Create a repository without a working directory, as used on servers",
+		"-b":               "Name of the initial branch",
+		"--initial-branch": "Name of the initial branch",
+	},
+	"git add": {
+		"-A":              "Stage all changes, including deletions and new files, in the whole tree",
+		"--all":           "Stage all changes, including deletions and new files, in the whole tree",
+		"-p":              "Pick the hunks to stage interactively",
+		"--patch":         "Pick the hunks to stage interactively",
+		"-u":              "Stage modifications and deletions of tracked files only",
+		"--update":        "Stage modifications and deletions of tracked files only",
+		"-n":              "Show what would be staged without staging it",
+		"--dry-run":       "Show what would be staged without staging it",
+		"-f":              "Stage files even if they are ignored",
+		"--force":         "Stage files even if they are ignored",
+		"--intent-to-add": "Record that the path will be added later",
+	},
+	"git commit": {
+		"-m":            "Use the following text as the commit message",
+		"--message":     "Use the following text as the commit message",
+		"-a":            "Stage every modified tracked file before committing",
+		"--all":         "Stage every modified tracked file before committing",
+		"--amend":       "Replace the last commit instead of adding a new one",
+		"--no-edit":     "Keep the existing commit message",
+		"--no-verify":   "Skip the pre-commit and commit-msg hooks",
+		"-n":            "Skip the pre-commit and commit-msg hooks",
+		"-s":            "Add a Signed-off-by trailer",
+		"--signoff":     "Add a Signed-off-by trailer",
+		"-S":            "GPG-sign the commit",
+		"--fixup":       "Create a commit that fixes up the given commit during an autosquash rebase",
+		"--allow-empty": "Allow a commit that changes nothing",
+	},
+	"git status": {
+		"-s":          "Show the short format",
+		"--short":     "Show the short format",
+		"-b":          "Show the branch and tracking information",
+		"--branch":    "Show the branch and tracking information",
+		"--porcelain": "Machine-readable output that stays stable across versions",
+		"--ignored":   "Also list ignored files",
+	},
+	"git branch": {
+		"-a":                "List local and remote-tracking branches",
+		"--all":             "List local and remote-tracking branches",
+		"-r":                "List remote-tracking branches",
+		"-d":                "Delete a branch that has been merged",
+		"--delete":          "Delete a branch that has been merged",
+		"-D":                "Delete a branch even if it is not merged",
+		"-m":                "Rename a branch",
+		"--move":            "Rename a branch",
+		"-M":                "Rename a branch even if the new name exists",
+		"-v":                "Show the last commit of each branch",
+		"-vv":               "Show the last commit and upstream of each branch",
+		"-u":                "Set the upstream of the branch",
+		"--set-upstream-to": "Set the upstream of the branch",
+		"--merged":          "List branches merged into the current one",
+		"--no-merged":       "List branches not yet merged into the current one",
+	},
+	"git merge": {
+		"--no-ff":                     "Always create a merge commit, even when a fast-forward is possible",
+		"--ff-only":                   "Refuse to merge unless the branch can be fast-forwarded",
+		"--squash":                    "Combine the changes into the working tree without creating a merge commit",
+		"--abort":                     "Stop the merge and go back to the state before it",
+		"--continue":                  "Conclude the merge after resolving conflicts",
+		"-m":                          "Use the following text as the merge commit message",
+		"--allow-unrelated-histories": "Allow merging branches that share no common commit",
+	},
+	"git pull": {
+		"--rebase":                    "Rebase local commits on top of the fetched branch instead of merging",
+		"-r":                          "Rebase local commits on top of the fetched branch instead of merging",
+		"--no-rebase":                 "Merge the fetched branch",
+		"--ff-only":                   "Only update if the branch can be fast-forwarded",
+		"--autostash":                 "Stash local changes before and reapply them after",
+		"--allow-unrelated-histories": "Allow merging branches that share no common commit",
+	},
+	"git push": {
+		"-u":                 "Remember the remote branch as the upstream of the local one",
+		"--set-upstream":     "Remember the remote branch as the upstream of the local one",
+		"-f":                 "Overwrite the remote branch even if that discards commits",
+		"--force":            "Overwrite the remote branch even if that discards commits",
+		"--force-with-lease": "Overwrite the remote branch only if nobody else pushed to it since your last fetch",
+		"--tags":             "Push all tags as well",
+		"--all":              "Push all branches",
+		"-d":                 "Delete the named branch or tag on the remote",
+		"--delete":           "Delete the named branch or tag on the remote",
+		"--dry-run":          "Show what would be pushed without pushing",
+		"-n":                 "Show what would be pushed without pushing",
+		"--no-verify":        "Skip the pre-push hook",
+		"--mirror":           "Make the remote an exact copy of all local refs",
+	},
+	"git log": {
+		"--oneline":  "Show each commit on a single line",
+		"--graph":    "Draw the branch structure next to the commits",
+		"--all":      "Show commits from every branch, not just the current one",
+		"-p":         "Show the patch of each commit",
+		"--patch":    "Show the patch of each commit",
+		"--stat":     "Show which files each commit changed",
+		"-n":         "Limit the number of commits",
+		"--author":   "Only show commits by a matching author",
+		"--since":    "Only show commits newer than a date",
+		"--grep":     "Only show commits whose message matches",
+		"--follow":   "Continue listing the history of a file beyond renames",
+		"-L":         "Trace the history of a range of lines",
+		"--decorate": "Show branch and tag names next to commits",
+		"--format":   "Use a custom output format",
+		"--pretty":   "Use a named or custom output format",
+	},
+	"git clone": {
+		"--depth":              "Fetch only the given number of recent commits",
+		"-b":                   "Check out the given branch instead of the default",
+		"--branch":             "Check out the given branch instead of the default",
+		"--bare":               "Clone without a working directory",
+		"--recurse-submodules": "Also clone the submodules",
+		"--single-branch":      "Fetch only one branch",
+	},
+	"git remote": {
+		"-v":        "Show the URLs of each remote",
+		"--verbose": "Show the URLs of each remote",
+	},
+	"git fetch": {
+		"--all":   "Fetch from every remote",
+		"-p":      "Delete remote-tracking branches that no longer exist on the remote",
+		"--prune": "Delete remote-tracking branches that no longer exist on the remote",
+		"--tags":  "Fetch all tags",
+	},
+	"git reset": {
+		"--soft":  "Move the branch only; keep the index and working directory",
+		"--mixed": "Move the branch and reset the index; keep the working directory (default)",
+		"--hard":  "Move the branch and overwrite the index and working directory",
+		"--keep":  "Like --hard, but refuse if local changes would be lost",
+		"--merge": "Reset, keeping local changes that are not in the index",
+	},
+	"git tag": {
+		"-a":         "Create an annotated tag with author and message",
+		"--annotate": "Create an annotated tag with author and message",
+		"-m":         "Use the following text as the tag message",
+		"-d":         "Delete a tag",
+		"--delete":   "Delete a tag",
+		"-s":         "Create a GPG-signed tag",
+		"-l":         "List tags matching a pattern",
+		"--list":     "List tags matching a pattern",
+		"-f":         "Replace an existing tag",
+	},
+	"git rebase": {
+		"-i":            "Edit the list of commits to replay before starting",
+		"--interactive": "Edit the list of commits to replay before starting",
+		"--onto":        "Replay the commits onto a different base",
+		"--continue":    "Continue after resolving a conflict",
+		"--abort":       "Stop the rebase and return to the original branch",
+		"--skip":        "Skip the commit that caused the conflict",
+		"--autosquash":  "Apply fixup! and squash! commits automatically",
+		"--autostash":   "Stash local changes before and reapply them after",
+	},
+	"git cherry-pick": {
+		"-x":          "Append a line recording the original commit to the message",
+		"-e":          "Edit the commit message before committing",
+		"-n":          "Apply the changes without committing",
+		"--no-commit": "Apply the changes without committing",
+		"-m":          "Pick the given parent of a merge commit as the mainline",
+		"--continue":  "Continue after resolving a conflict",
+		"--abort":     "Stop and return to the state before the cherry-pick",
+	},
+	"git stash": {
+		"-u":                  "Also stash untracked files",
+		"--include-untracked": "Also stash untracked files",
+		"-m":                  "Describe the stash with a message",
+		"--keep-index":        "Leave staged changes in the index",
+		"-p":                  "Pick the hunks to stash interactively",
+	},
+	"git revert": {
+		"-m":          "Revert a merge commit relative to the given parent",
+		"--no-edit":   "Use the default revert message",
+		"-n":          "Apply the inverse changes without committing",
+		"--no-commit": "Apply the inverse changes without committing",
+	},
+	"git filter-branch": {
+		"--tree-filter":  "Run a command on the checked-out tree of every commit",
+		"--index-filter": "Run a command on the index of every commit (faster)",
+		"--msg-filter":   "Rewrite every commit message",
+		"--prune-empty":  "Drop commits that become empty",
+		"-f":             "Overwrite an earlier backup in refs/original",
+	},
+	"docker run": {
+		"-d":           "Run the container in the background",
+		"--detach":     "Run the container in the background",
+		"-i":           "Keep standard input open",
+		"-t":           "Allocate a terminal",
+		"-it":          "Run interactively with a terminal",
+		"--rm":         "Remove the container when it exits",
+		"--name":       "Give the container a name",
+		"-p":           "Publish a container port on the host",
+		"--publish":    "Publish a container port on the host",
+		"-v":           "Mount a volume or host directory",
+		"--volume":     "Mount a volume or host directory",
+		"--mount":      "Mount a volume, host directory or tmpfs using key=value syntax",
+		"-e":           "Set an environment variable",
+		"--env":        "Set an environment variable",
+		"--env-file":   "Read environment variables from a file",
+		"--network":    "Connect the container to a network",
+		"-w":           "Working directory inside the container",
+		"--workdir":    "Working directory inside the container",
+		"-u":           "User to run as inside the container",
+		"--user":       "User to run as inside the container",
+		"--entrypoint": "Override the image's entrypoint",
+		"--restart":    "Restart policy when the container exits",
+		"-m":           "Memory limit",
+		"--memory":     "Memory limit",
+		"--cpus":       "Number of CPUs the container may use",
+		"--platform":   "Run an image built for another platform",
+
+		"--memory-swap":        "Memory plus swap limit",
+		"--memory-reservation": "Soft memory limit, enforced when the host runs low on memory",
+		"--shm-size":           "Size of /dev/shm",
+		"--cpuset-cpus":        "CPU cores the container may run on",
+		"-P":                   "Publish every port the image exposes on random host ports",
+		"--publish-all":        "Publish every port the image exposes on random host ports",
+	},
+	"docker build": {
+		"-t":          "Name and tag of the resulting image",
+		"--tag":       "Name and tag of the resulting image",
+		"-f":          "Path of the Dockerfile",
+		"--file":      "Path of the Dockerfile",
+		"--build-arg": "Set a build-time ARG variable",
+		"--no-cache":  "Do not use cached layers",
+		"--target":    "Stop at the given stage of a multi-stage build",
+		"--pull":      "Always pull a newer base image",
+		"--platform":  "Build for the given platform",
+	},
+	"docker push": {
+		"-a":         "Push all tags of the repository",
+		"--all-tags": "Push all tags of the repository",
+	},
+	"docker compose": {
+		"-d":        "Start the services in the background",
+		"--build":   "Build images before starting",
+		"-v":        "Also remove named volumes",
+		"--volumes": "Also remove named volumes",
+		"-f":        "Use another Compose file",
+		"--file":    "Use another Compose file",
+	},
+	"kubectl get": {
+		"-n":               "Look in this namespace instead of the context's default",
+		"--namespace":      "Look in this namespace instead of the context's default",
+		"-A":               "List the resources of every namespace",
+		"--all-namespaces": "List the resources of every namespace",
+		"-o":               "Output format: wide, yaml, json, name or jsonpath=...",
+		"--output":         "Output format: wide, yaml, json, name or jsonpath=...",
+		"-l":               "Only list resources with these labels, e.g. app=web",
+		"--selector":       "Only list resources with these labels, e.g. app=web",
+		"-w":               "Keep watching and print changes",
+		"--watch":          "Keep watching and print changes",
+	},
+	"kubectl apply": {
+		"-f":          "Apply the resources in this file, directory or URL",
+		"--filename":  "Apply the resources in this file, directory or URL",
+		"-k":          "Apply a kustomization directory",
+		"-n":          "Namespace for resources that do not name one",
+		"--namespace": "Namespace for resources that do not name one",
+		"--dry-run":   "Only show what would be applied: client or server",
+		"--prune":     "Delete resources that are no longer in the files (with -l)",
+	},
+	"kubectl delete": {
+		"-f":             "Delete the resources defined in this file",
+		"--filename":     "Delete the resources defined in this file",
+		"-n":             "Delete in this namespace",
+		"--namespace":    "Delete in this namespace",
+		"-l":             "Delete every resource with these labels",
+		"--selector":     "Delete every resource with these labels",
+		"--all":          "Delete every resource of the kind in the namespace",
+		"--force":        "Remove pods from the API at once, without waiting for them to stop",
+		"--grace-period": "Seconds to give the pods to shut down",
+	},
+	"kubectl logs": {
+		"-f":          "Keep printing new output",
+		"--follow":    "Keep printing new output",
+		"-c":          "Container of the pod to read",
+		"--container": "Container of the pod to read",
+		"-p":          "Output of the previous, crashed run of the container",
+		"--previous":  "Output of the previous, crashed run of the container",
+		"--since":     "Only output newer than this, e.g. 10m",
+		"--tail":      "Only the last lines, e.g. 100",
+		"-n":          "Namespace of the pod",
+		"--namespace": "Namespace of the pod",
+	},
+	"kubectl exec": {
+		"-i":          "Keep standard input open",
+		"--stdin":     "Keep standard input open",
+		"-t":          "Allocate a terminal",
+		"--tty":       "Allocate a terminal",
+		"-c":          "Container of the pod to run in",
+		"--container": "Container of the pod to run in",
+		"-n":          "Namespace of the pod",
+		"--namespace": "Namespace of the pod",
+	},
+	"kubectl port-forward": {
+		"--address":   "Local addresses to listen on; only localhost by default",
+		"-n":          "Namespace of the pod or service",
+		"--namespace": "Namespace of the pod or service",
+	},
+}
+
+// hasSubcommands lists the topics whose first argument selects an action,
+// as in "git stash pop" or "docker volume rm".
+var hasSubcommands = map[topicRef]bool{
+	gitCommandTopic("remote"):        true,
+	gitAdvancedTopic("stash"):        true,
+	gitAdvancedTopic("submodule"):    true,
+	gitAdvancedTopic("bisect"):       true,
+	gitAdvancedTopic("gitflow"):      true,
+	dockerAdvancedTopic("compose"):   true,
+	dockerAdvancedTopic("swarm"):     true,
+	dockerAdvancedTopic("network"):   true,
+	dockerAdvancedTopic("volume"):    true,
+	kubectlAdvancedTopic("rollout"):  true,
+	kubectlAdvancedTopic("contexts"): true,
+	kubectlAdvancedTopic("rbac"):     true,
+}
+
+// printCommandBreakdown explains a command line word by word: the topic it
+// belongs to, every flag we know about and the operands.
+func printCommandBreakdown(c commandLine) {
+	fmt.Printf("$ %s\n", c.line)
+	if c.alias != "" {
+		fmt.Printf("  ('%s' is a Git alias for: %s)\n", c.alias, strings.Join(quoteWords(c.words), " "))
+	}
+	fmt.Print("\n")
+
+	head := strings.Join(c.words[:len(c.words)-len(c.args)], " ")
+	fmt.Printf("  %-24s %s\n", head, topicSummary(c.topic))
+
+	descriptions := flagDescriptions[c.topic.tool+" "+c.topic.name]
+	for i := 0; i < len(c.args); i++ {
+		a := c.args[i]
+		switch {
+		case isRedirection(a):
+			fmt.Printf("  %-24s %s\n", a, "Shell redirection, handled by the shell rather than "+c.topic.tool)
+		case a == "--":
+			fmt.Printf("  %-24s %s\n", a, "Everything after this is a path, not a flag")
+		case strings.HasPrefix(a, "-") && len(a) > 1:
+			name, value, hasValue := strings.Cut(a, "=")
+			if !hasValue && c.takesValue(name) && i+1 < len(c.args) {
+				value, hasValue = c.args[i+1], true
+				i++
+			}
+			word := name
+			if hasValue {
+				word = name + " " + shellQuote(value)
+			}
+			description, ok := descriptions[name]
+			if !ok {
+				description = "Flag not covered by explain; see the tool's manual"
+			}
+			fmt.Printf("  %-24s %s\n", word, description)
+			if parse, ok := dockerFlagParsers[name]; ok && hasValue && c.topic == dockerCommandTopic("run") {
+				if v, err := parse(value); err == nil {
+					printFlagValue(v, "      ")
+				} else {
+					fmt.Printf("      !!! %v\n", err)
+				}
+			}
+		case i == 0 && hasSubcommands[c.topic]:
+			fmt.Printf("  %-24s %s\n", a, "Subcommand")
+		default:
+			fmt.Printf("  %-24s %s\n", a, "Argument")
+		}
+	}
+
+	for _, r := range matchRisks(c.topic, c.args) {
+		fmt.Print("\n")
+		printRiskWarning(r, "")
+	}
+	fmt.Print("\n")
+	fmt.Printf("See: %s\n", c.topic)
+}
+
+// isRedirection reports whether word is a shell redirection such as 2>&1,
+// >out.txt or &>log.
+func isRedirection(word string) bool {
+	word = strings.TrimLeft(word, "0123456789&")
+	return strings.HasPrefix(word, ">") || strings.HasPrefix(word, "<")
+}
+
+// shellQuote quotes s for display if a shell would split it.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"$`\\|&;<>*?") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func topicSummary(t topicRef) string {
+	for _, topic := range topics {
+		if topic.ref == t {
+			return topic.summary
+		}
+	}
+	return ""
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import "strings"
+
+// commandLine is a git, docker or kubectl command line split into words and matched
+// to the topic that explains it.
+type commandLine struct {
+	line  string
+	words []string
+	topic topicRef
+	args  []string // the words after the subcommand
+	alias string   // the git alias the line was written with, if any
+}
+
+// valueFlags lists the flags that consume the following word as their
+// value, so that it is not mistaken for an operand. Entries are keyed by
+// "tool topic", falling back to the tool alone.
+var valueFlags = map[string]map[string]bool{
+	"git commit":      {"-m": true, "--message": true, "-F": true, "--file": true, "-C": true, "-c": true},
+	"git init":        {"-b": true, "--initial-branch": true},
+	"git log":         {"-n": true, "--max-count": true, "--author": true, "--since": true, "--until": true, "--grep": true, "-L": true},
+	"git tag":         {"-m": true, "--message": true, "-F": true, "--file": true},
+	"git merge":       {"-m": true, "-F": true, "--file": true, "-s": true, "--strategy": true, "-X": true},
+	"git clone":       {"-b": true, "--branch": true, "-o": true, "--origin": true, "--depth": true},
+	"git rebase":      {"--onto": true, "-x": true, "--exec": true, "-s": true, "-X": true},
+	"git cherry-pick": {"-m": true, "--mainline": true},
+	"git revert":      {"-m": true, "--mainline": true},
+	"git stash":       {"-m": true, "--message": true},
+	"git submodule":   {"-b": true, "--branch": true, "--name": true},
+	"git remote":      {"-t": true, "-m": true},
+	"docker compose":  {"-f": true, "--file": true, "-p": true, "--project-name": true, "--env-file": true, "-t": true, "--timeout": true},
+	"kubectl": {
+		"-n": true, "--namespace": true, "-f": true, "--filename": true,
+		"-l": true, "--selector": true, "-o": true, "--output": true,
+		"-c": true, "--container": true, "--context": true, "--address": true,
+		"--to-revision": true, "--since": true, "--tail": true, "--as": true,
+		"--timeout": true, "--grace-period": true,
+	},
+	"docker": {
+		"-t": true, "--tag": true, "-f": true, "--file": true, "-v": true,
+		"--volume": true, "-p": true, "--publish": true, "-e": true, "--env": true,
+		"--name": true, "--network": true, "-w": true, "--workdir": true,
+		"--mount": true, "--entrypoint": true, "-u": true, "--user": true,
+		"--memory": true, "-m": true, "--cpus": true, "--driver": true,
+		"--build-arg": true, "--target": true, "--platform": true, "--token": true,
+		"--advertise-addr": true, "--env-file": true, "--label": true, "-l": true,
+		"--restart": true, "--hostname": true, "-h": true, "--memory-swap": true,
+		"--memory-reservation": true, "--shm-size": true, "--cpuset-cpus": true,
+	},
+}
+
+func (c commandLine) takesValue(flag string) bool {
+	if flags, ok := valueFlags[c.topic.tool+" "+c.topic.name]; ok {
+		return flags[flag]
+	}
+	return valueFlags[c.topic.tool][flag]
+}
+
+// parseCommandLine splits line like a shell would and finds its topic, e.g.
+// "git reset --hard HEAD~2" is explained by "explain git --command reset".
+func parseCommandLine(line string) (commandLine, bool) {
+	words := splitCommandLine(line)
+	if len(words) == 0 {
+		return commandLine{}, false
+	}
+	if words[0] == "docker-compose" {
+		return commandLine{line: line, words: words, topic: dockerAdvancedTopic("compose"), args: words[1:]}, true
+	}
+	if len(words) < 2 {
+		return commandLine{}, false
+	}
+
+	if t, ok := findCommandTopic(words[0], words[1]); ok {
+		return commandLine{line: line, words: words, topic: t, args: words[2:]}, true
+	}
+	if expanded, alias, ok := expandGitAlias(words); ok && len(expanded) > 1 {
+		if t, ok := findCommandTopic(expanded[0], expanded[1]); ok {
+			return commandLine{line: line, words: expanded, topic: t, args: expanded[2:], alias: alias}, true
+		}
+	}
+	return commandLine{}, false
+}
+
+func findCommandTopic(tool, name string) (topicRef, bool) {
+	if alias, ok := topicAliases[tool][name]; ok {
+		name = alias
+	}
+	for _, t := range topics {
+		if t.ref.tool == tool && t.ref.name == name {
+			return t.ref, true
+		}
+	}
+	return topicRef{}, false
+}
+
+// topicForCommandLine finds the topic explaining a command line and returns
+// the arguments following the subcommand.
+func topicForCommandLine(line string) (topicRef, []string, bool) {
+	c, ok := parseCommandLine(line)
+	return c.topic, c.args, ok
+}
+
+// operands returns the arguments that are neither flags nor flag values.
+func (c commandLine) operands() []string {
+	var operands []string
+	for i := 0; i < len(c.args); i++ {
+		a := c.args[i]
+		if a == "--" {
+			return append(operands, c.args[i+1:]...)
+		}
+		if strings.HasPrefix(a, "-") && len(a) > 1 {
+			if c.takesValue(a) {
+				i++
+			}
+			continue
+		}
+		operands = append(operands, a)
+	}
+	return operands
+}
+
+// operand returns the i-th operand, or placeholder if there are fewer.
+func (c commandLine) operand(i int, placeholder string) string {
+	operands := c.operands()
+	if i < len(operands) {
+		return operands[i]
+	}
+	return placeholder
+}
+
+// flag returns the value of the first of names present on the command line.
+// Both "--name value" and "--name=value" forms are recognised.
+func (c commandLine) flag(names ...string) (string, bool) {
+	for i, a := range c.args {
+		for _, name := range names {
+			if a == name {
+				if i+1 < len(c.args) && c.takesValue(name) {
+					return c.args[i+1], true
+				}
+				return "", true
+			}
+			if value, ok := strings.CutPrefix(a, name+"="); ok {
+				return value, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (c commandLine) hasFlag(names ...string) bool {
+	_, ok := c.flag(names...)
+	return ok
+}
+
+// composeBinary returns how the line invoked Compose: "docker-compose" for
+// the standalone v1 binary, "docker compose" for the plugin.
+func (c commandLine) composeBinary() string {
+	if len(c.words) > 0 && c.words[0] == "docker-compose" {
+		return "docker-compose"
+	}
+	return "docker compose"
+}
+
+// splitCommandLine splits line into words, honouring single quotes, double
+// quotes and backslash escapes the way a POSIX shell does.
+func splitCommandLine(line string) []string {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			// A backslash before a newline continues the line.
+			if r != '\n' {
+				current.WriteRune(r)
+			}
+			escaped = false
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' {
+				escaped = true
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words
+}
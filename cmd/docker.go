@@ -16,9 +16,19 @@ For example:
 - Giving extensive information about advanced Docker features`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) > 0 {
-			fmt.Println(`Docker command has no subcommands. Please provide one of the following flags:
+			fmt.Printf(`Unknown Docker subcommand '%s'. Please use one of the following subcommands:
+compose-migrate
+flag
+ignore
+image
+image-tar
+inspect
+lint
+
+Or provide one of the following flags:
 --command
---advanced`)
+--advanced
+`, args[0])
 			return
 		}
 
@@ -54,18 +64,33 @@ func explainDockerCommand(command string) {
 		fmt.Println("The 'docker run' command runs a command in a new container.")
 		fmt.Println("Example: docker run -it ubuntu bash")
 		fmt.Println("This command runs an interactive shell in a new Ubuntu container.")
+		fmt.Print("\n")
+		fmt.Println("Flags such as -p, -v and --mount take values made of several parts. For example, -p 127.0.0.1:8080:80:")
+		if v, err := parsePublishFlag("127.0.0.1:8080:80"); err == nil {
+			printFlagValue(v, "  ")
+		}
+		fmt.Println("To label the parts of your own values:\n$ explain docker flag -v ./data:/data:ro")
 	case "build":
 		fmt.Println("The 'docker build' command builds an image from a Dockerfile.")
 		fmt.Println("Example: docker build -t my-image .")
 		fmt.Println("This command builds a Docker image named 'my-image' from the current directory.")
+		fmt.Println("The directory ('.') is the build context: everything in it, minus what .dockerignore excludes, is sent to the daemon before the build starts.")
+		fmt.Print("\n")
+		fmt.Println("To see what the build context contains and how large it is:\n$ explain docker ignore")
+		fmt.Println("To see which instruction produced each layer of the result, and how big it is:\n$ docker save -o my-image.tar my-image && explain docker image-tar my-image.tar")
+		fmt.Println("To check the Dockerfile for common mistakes before you build:\n$ explain docker lint Dockerfile")
 	case "push":
 		fmt.Println("The 'docker push' command pushes an image or a repository to a registry.")
 		fmt.Println("Example: docker push my-registry/my-image:latest")
 		fmt.Println("This command pushes the 'my-image' image to the 'my-registry' registry with the 'latest' tag.")
+		fmt.Print("\n")
+		fmt.Println("The image name decides where it is pushed. To see which registry and repository a name points to:\n$ explain docker image my-registry/my-image:latest")
 	// Add more cases for other Docker commands
 	default:
 		fmt.Printf("Explanation for '%s' Docker command is not available. Try another Docker command.\n", command)
 	}
+
+	printRiskWarnings(dockerCommandTopic(command))
 }
 
 func explainAdvancedDockerConcepts(command string) {
@@ -74,16 +99,14 @@ func explainAdvancedDockerConcepts(command string) {
 		fmt.Println("- Docker Compose is a tool for defining and running multi-container Docker applications.")
 		fmt.Println("It allows you to define the services, networks, and volumes in a YAML file, and then spin up the entire application stack with a single command.")
 		fmt.Print("\n")
-		fmt.Println(`Here’s a summary of the different commands associated with Docker Compose:
-docker-compose up               Build and start the entire application stack
-docker-compose down             Stop and remove the entire application stack
-docker-compose ps               List the status of containers defined in the Docker Compose file
-docker-compose logs             View output from containers
-docker-compose exec <service>   Run a command in a running service container`)
+		fmt.Println("Compose comes in two forms: the docker compose plugin (Compose v2), and the older standalone docker-compose binary (Compose v1), which no longer receives updates. The commands are the same apart from the hyphen:")
+		printComposeCommands()
 		fmt.Print("\n")
 		fmt.Println("Example:")
-		fmt.Println("Create a docker-compose.yml file defining services and then run:")
-		fmt.Println("$ docker-compose up")
+		fmt.Println("Create a compose.yaml file defining services and then run:")
+		fmt.Println("$ docker compose up")
+		fmt.Print("\n")
+		fmt.Println("To see what to change in a file written for docker-compose:\n$ explain docker compose-migrate docker-compose.yml")
 	case "swarm":
 		fmt.Println("- Docker Swarm is a native clustering and orchestration solution for Docker.")
 		fmt.Println("It turns a pool of Docker hosts into a single, virtual Docker host.")
@@ -106,6 +129,8 @@ docker stack deploy             Deploy a new stack or update an existing stack`)
 docker network create            Create a new Docker network
 docker network ls                List Docker networks
 docker network inspect           Display detailed information about a Docker network`)
+		fmt.Print("\n")
+		fmt.Println("To have the JSON of docker network inspect explained, including which containers are connected:\n$ docker network inspect my-network | explain docker inspect")
 		fmt.Print("\n")
 		fmt.Println("Example:")
 		fmt.Println("Create a new bridge network:")
@@ -118,6 +143,8 @@ docker network inspect           Display detailed information about a Docker net
 docker volume create             Create a new Docker volume
 docker volume ls                 List Docker volumes
 docker volume inspect            Display detailed information about a Docker volume`)
+		fmt.Print("\n")
+		fmt.Println("To have the JSON of docker volume inspect explained, including where the data lives on the host:\n$ docker volume inspect my-data-volume | explain docker inspect")
 		fmt.Print("\n")
 		fmt.Println("Example:")
 		fmt.Println("Create a new named volume:")
@@ -125,4 +152,6 @@ docker volume inspect            Display detailed information about a Docker vol
 	default:
 		fmt.Printf("Explanation for '%s' Docker concept is not available. Try another advanced Docker concept.\n", command)
 	}
+
+	printRiskWarnings(dockerAdvancedTopic(command))
 }
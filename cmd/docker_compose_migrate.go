@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var dockerComposeMigrateCmd = &cobra.Command{
+	Use:   "compose-migrate [file]",
+	Short: "Explains what to change in a Compose file to move from docker-compose to docker compose",
+	Long: `This command reads a Compose file written for the standalone docker-compose
+binary (Compose v1) and lists what changes with the docker compose plugin
+(Compose v2): the obsolete version key, links, container_name conflicts, extends,
+external volume and network names, and resource limits that start to apply.
+Each finding explains why and what to write instead. It ends with the commands
+in both forms.
+For example:
+
+- explain docker compose-migrate
+- explain docker compose-migrate docker-compose.prod.yml`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		file := "docker-compose.yml"
+		if len(args) == 1 {
+			file = args[0]
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Println("Could not read the Compose file:", err)
+			return
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			fmt.Println("Could not parse the Compose file:", err)
+			return
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			fmt.Printf("%s does not contain a Compose mapping.\n", file)
+			return
+		}
+		printComposeMigration(file, checkComposeFile(doc.Content[0]))
+	},
+}
+
+func init() {
+	dockerCmd.AddCommand(dockerComposeMigrateCmd)
+}
+
+// yamlLookup returns the key and value nodes of key in a mapping node, or
+// nils if n is not a mapping or has no such key.
+func yamlLookup(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// yamlPath follows a chain of mapping keys, as in yamlPath(svc, "deploy", "replicas").
+func yamlPath(n *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		_, n = yamlLookup(n, key)
+	}
+	return n
+}
+
+// yamlPairs returns the keys of a mapping node with their values, in file order.
+func yamlPairs(n *yaml.Node) [][2]*yaml.Node {
+	var pairs [][2]*yaml.Node
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{n.Content[i], n.Content[i+1]})
+	}
+	return pairs
+}
+
+// yamlStrings returns the scalar values of a sequence node.
+func yamlStrings(n *yaml.Node) []string {
+	var values []string
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, item := range n.Content {
+		if item.Kind == yaml.ScalarNode {
+			values = append(values, item.Value)
+		}
+	}
+	return values
+}
+
+type composeChange struct {
+	line    int
+	subject string
+	why     string
+	change  string
+	warning bool // the file behaves differently or fails with docker compose
+}
+
+type composeCheck struct {
+	version  string
+	services []string
+	changes  []composeChange
+}
+
+func (c *composeCheck) add(at *yaml.Node, warning bool, subject, why, change string) {
+	c.changes = append(c.changes, composeChange{line: at.Line, subject: subject, why: why, change: change, warning: warning})
+}
+
+func checkComposeFile(root *yaml.Node) composeCheck {
+	var c composeCheck
+	versionKey, version := yamlLookup(root, "version")
+	_, services := yamlLookup(root, "services")
+
+	if versionKey != nil {
+		c.version = version.Value
+		c.add(versionKey, false, fmt.Sprintf("version: %q", version.Value),
+			"docker compose implements the Compose Specification, which merges file formats 2 and 3 and has no version. It ignores the key and warns that it is obsolete.",
+			"Delete the line.")
+	}
+	if services == nil && versionKey == nil {
+		// Format 1 files have no version and list the services at the top level.
+		services = root
+		c.version = "1"
+		c.add(root, true, "Services at the top level (file format 1)",
+			"docker compose only reads services under a services: key. Format 1 also put every service on the default bridge network, which is why those files need links.",
+			"Add a line 'services:' at the top and indent everything below it by two spaces.")
+	}
+
+	names := map[string][]string{} // container_name → services
+	var firstName []*yaml.Node
+	for _, pair := range yamlPairs(services) {
+		name, svc := pair[0].Value, pair[1]
+		c.services = append(c.services, name)
+		checkComposeService(&c, name, svc)
+		if key, value := yamlLookup(svc, "container_name"); key != nil {
+			if len(names[value.Value]) == 0 {
+				firstName = append(firstName, value)
+			}
+			names[value.Value] = append(names[value.Value], name)
+		}
+	}
+	for _, value := range firstName {
+		if users := names[value.Value]; len(users) > 1 {
+			c.add(value, true, fmt.Sprintf("container_name %s is used by %s", value.Value, strings.Join(users, " and ")),
+				"Container names are unique on the host, so only the first of these services can start.",
+				"Give each service its own container_name, or remove it and let Compose name the containers.")
+		}
+	}
+	if len(firstName) > 0 {
+		c.add(firstName[0], false, "container_name is global on the host",
+			"Compose normally prefixes names with the project, so two checkouts of the same project, or the same file started with -p, run side by side. A fixed container_name makes the second 'up' fail with 'Conflict. The container name is already in use'.",
+			"Remove container_name where nothing outside Compose relies on the name. Other services reach it by its service name anyway.")
+	}
+
+	for _, section := range []string{"volumes", "networks"} {
+		_, top := yamlLookup(root, section)
+		for _, pair := range yamlPairs(top) {
+			externalKey, external := yamlLookup(pair[1], "external")
+			if name := yamlPath(external, "name"); name != nil {
+				c.add(externalKey, false, fmt.Sprintf("%s.%s.external.name", section, pair[0].Value),
+					"external: with a name below it is deprecated; the Compose Specification moved the name up a level.",
+					fmt.Sprintf("%s:\n  %s:\n    name: %s\n    external: true", section, pair[0].Value, name.Value))
+			}
+		}
+	}
+
+	sort.SliceStable(c.changes, func(i, j int) bool { return c.changes[i].line < c.changes[j].line })
+	return c
+}
+
+func checkComposeService(c *composeCheck, name string, svc *yaml.Node) {
+	if key, links := yamlLookup(svc, "links"); key != nil {
+		var targets, aliases []string
+		for _, link := range yamlStrings(links) {
+			target, alias, hasAlias := strings.Cut(link, ":")
+			targets = append(targets, target)
+			if hasAlias && alias != target {
+				aliases = append(aliases, fmt.Sprintf("%s (as %s)", target, alias))
+			}
+		}
+		change := fmt.Sprintf("Remove links. If %s must start after them, write:\n  depends_on: [%s]", name, strings.Join(targets, ", "))
+		if len(aliases) > 0 {
+			change += fmt.Sprintf("\nWhere a link gave another name, add it as a network alias of the target service:\n  networks:\n    default:\n      aliases: [<alias>]\nThis file uses: %s", strings.Join(aliases, ", "))
+		}
+		c.add(key, false, fmt.Sprintf("%s.links: %s", name, strings.Join(yamlStrings(links), ", ")),
+			"links is a legacy option. Every service of the project joins the project's default network and reaches the others by their service name, without links.",
+			change)
+	}
+
+	if key, extends := yamlLookup(svc, "extends"); key != nil {
+		base := extends.Value
+		if s := yamlPath(extends, "service"); s != nil {
+			base = s.Value
+		}
+		from := "this file"
+		if f := yamlPath(extends, "file"); f != nil {
+			from = f.Value
+		}
+		why := "extends was only supported in file format 2; docker-compose rejected it in format 3 files. docker compose supports it in every file, so it keeps working after the migration."
+		if strings.HasPrefix(c.version, "3") {
+			why = "docker-compose rejected extends in format 3 files, so this file only ever worked with docker compose, which supports extends in every file."
+		}
+		c.add(key, false, fmt.Sprintf("%s extends %s from %s", name, base, from), why+" Paths in the extended service are resolved relative to the file that defines it.",
+			"Nothing to change. To see the merged result: docker compose config")
+	}
+
+	if replicas := yamlPath(svc, "deploy", "replicas"); replicas != nil {
+		if n, _ := strconv.Atoi(replicas.Value); n > 1 {
+			if key, _ := yamlLookup(svc, "container_name"); key != nil {
+				c.add(key, true, fmt.Sprintf("%s has container_name and %d replicas", name, n),
+					"docker-compose ignored deploy.replicas, but docker compose starts that many containers, and they cannot all have the same name.",
+					"Remove container_name from "+name+".")
+			}
+		}
+	}
+	if key, resources := yamlLookup(yamlPath(svc, "deploy"), "resources"); key != nil && resources != nil {
+		c.add(key, true, name+".deploy.resources",
+			"docker-compose only applied deploy limits with --compatibility; docker compose applies them always. A service that used more memory or CPU than its limit before now gets throttled or killed.",
+			"Check the limits: docker stats shows what the services use.")
+	}
+}
+
+func printComposeMigration(file string, c composeCheck) {
+	format := "Compose Specification"
+	if c.version != "" {
+		format = "Compose file format " + c.version
+	}
+	fmt.Printf("%s: %s with %s: %s\n", file, format, plural(len(c.services), "service"), strings.Join(c.services, ", "))
+	fmt.Print("\n")
+
+	if len(c.changes) == 0 {
+		fmt.Println("Nothing in this file needs to change for docker compose.")
+		fmt.Print("\n")
+	}
+	for _, change := range c.changes {
+		if change.warning {
+			fmt.Printf("!!! Line %d: %s\n", change.line, change.subject)
+		} else {
+			fmt.Printf("Line %d: %s\n", change.line, change.subject)
+		}
+		fmt.Printf("  %s\n", change.why)
+		for i, line := range strings.Split(change.change, "\n") {
+			if i == 0 {
+				fmt.Printf("  Change: %s\n", line)
+			} else {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+		fmt.Print("\n")
+	}
+
+	fmt.Println("Container names change from underscores to hyphens: docker-compose named the first web container myapp_web_1, docker compose names it myapp-web-1. Update scripts that use those names, or better, use docker compose exec web.")
+	if base := filepath.Base(file); base == "docker-compose.yml" || base == "docker-compose.yaml" {
+		fmt.Printf("docker compose still finds %s, but looks for compose.yaml first.\n", base)
+	}
+	fmt.Print("\n")
+	printComposeCommands()
+	fmt.Println("The v1 binary no longer receives updates. The plugin comes with Docker Desktop; on Linux install the docker-compose-plugin package.")
+	fmt.Print("\n")
+	fmt.Printf("See: %s\n", dockerAdvancedTopic("compose"))
+}
+
+// composeCommands lists the common Compose commands for both binaries.
+var composeCommands = [][2]string{
+	{"up -d", "Build and start the entire application stack in the background"},
+	{"down", "Stop and remove the containers and networks of the stack"},
+	{"ps", "List the containers of the stack"},
+	{"logs -f", "Follow the output of the services"},
+	{"exec <service>", "Run a command in a running service container"},
+	{"build", "Build the images of the services"},
+	{"config", "Print the file with variables and extends resolved"},
+}
+
+func printComposeCommands() {
+	fmt.Printf("%-31s %-31s %s\n", "docker compose (v2 plugin)", "docker-compose (v1)", "What it does")
+	for _, c := range composeCommands {
+		fmt.Printf("%-31s %-31s %s\n", "docker compose "+c[0], "docker-compose "+c[0], c[1])
+	}
+}
@@ -0,0 +1,587 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var dockerFlagCmd = &cobra.Command{
+	Use:   "flag <flag> <value>...",
+	Short: "Explains each part of a docker run port, volume or resource flag",
+	Long: `This command labels every part of the values of docker run flags that are small
+languages of their own: published ports, volumes, mounts, and memory and CPU
+limits. Several flags can be given at once.
+For example:
+
+- explain docker flag -p 127.0.0.1:8080:80/udp
+- explain docker flag -v ./data:/data:ro,z
+- explain docker flag --mount type=tmpfs,target=/cache,tmpfs-size=64m
+- explain docker flag --memory 512m --cpus 1.5`,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+			cmd.Help()
+			return
+		}
+
+		printed := false
+		for i := 0; i < len(args); i++ {
+			name, value, hasValue := strings.Cut(args[i], "=")
+			if !hasValue {
+				if i+1 >= len(args) {
+					fmt.Printf("Please provide a value for %s, e.g. explain docker flag %s <value>.\n", name, name)
+					return
+				}
+				i++
+				value = args[i]
+			}
+			if printed {
+				fmt.Print("\n")
+			}
+			printDockerFlag(name, value)
+			printed = true
+		}
+	},
+}
+
+func init() {
+	dockerCmd.AddCommand(dockerFlagCmd)
+}
+
+// flagPart labels one piece of a flag value.
+type flagPart struct {
+	value   string
+	label   string
+	meaning string
+}
+
+type flagValue struct {
+	parts    []flagPart
+	warnings []string
+}
+
+func (f *flagValue) add(value, label, meaning string) {
+	f.parts = append(f.parts, flagPart{value: value, label: label, meaning: meaning})
+}
+
+func (f *flagValue) warn(format string, args ...any) {
+	f.warnings = append(f.warnings, fmt.Sprintf(format, args...))
+}
+
+// dockerFlagParsers split the values of docker run flags into labeled parts.
+var dockerFlagParsers = map[string]func(value string) (flagValue, error){
+	"-p":                   parsePublishFlag,
+	"--publish":            parsePublishFlag,
+	"-v":                   parseVolumeFlag,
+	"--volume":             parseVolumeFlag,
+	"--mount":              parseMountFlag,
+	"-m":                   parseMemoryFlag,
+	"--memory":             parseMemoryFlag,
+	"--memory-reservation": parseMemoryReservationFlag,
+	"--memory-swap":        parseMemorySwapFlag,
+	"--shm-size":           parseShmSizeFlag,
+	"--cpus":               parseCPUsFlag,
+	"--cpuset-cpus":        parseCPUSetFlag,
+}
+
+func printDockerFlag(name, value string) {
+	word := name + " " + shellQuote(value)
+	parse, ok := dockerFlagParsers[name]
+	if !ok {
+		fmt.Printf("Explanation for the value of '%s' is not available. Supported flags:\n", name)
+		fmt.Println("-p, --publish, -v, --volume, --mount, -m, --memory, --memory-reservation, --memory-swap, --shm-size, --cpus, --cpuset-cpus")
+		return
+	}
+	v, err := parse(value)
+	if err != nil {
+		fmt.Printf("%s: %v\n", word, err)
+		return
+	}
+
+	fmt.Printf("%s: %s\n", word, flagDescriptions["docker run"][name])
+	fmt.Print("\n")
+	printFlagValue(v, "  ")
+}
+
+func printFlagValue(v flagValue, indent string) {
+	for _, p := range v.parts {
+		value := p.value
+		if len(value) > 16 {
+			fmt.Printf("%s%s\n", indent, value)
+			value = ""
+		}
+		fmt.Printf("%s%-16s %-16s %s\n", indent, value, p.label, p.meaning)
+	}
+	for _, w := range v.warnings {
+		fmt.Printf("%s!!! %s\n", indent, w)
+	}
+}
+
+// parsePublishFlag parses [host-ip:][host-port:]container-port[/protocol].
+func parsePublishFlag(value string) (flagValue, error) {
+	var v flagValue
+	spec, protocol, hasProtocol := strings.Cut(value, "/")
+
+	var ip string
+	if strings.HasPrefix(spec, "[") {
+		end := strings.Index(spec, "]")
+		if end < 0 || end+1 >= len(spec) || spec[end+1] != ':' {
+			return v, fmt.Errorf("an IPv6 host address must be written as [address]:port")
+		}
+		ip, spec = spec[:end+1], spec[end+2:]
+	}
+	parts := strings.Split(spec, ":")
+	if ip == "" && len(parts) == 3 {
+		ip, parts = parts[0], parts[1:]
+	}
+	if len(parts) > 2 {
+		count := len(parts)
+		if ip != "" {
+			count++
+		}
+		return v, fmt.Errorf("expected [host-ip:][host-port:]container-port[/protocol], got %d parts separated by ':'", count)
+	}
+	hostPort, containerPort := "", parts[len(parts)-1]
+	if len(parts) == 2 {
+		hostPort = parts[0]
+	}
+	if !validPortRange(containerPort) {
+		return v, fmt.Errorf("'%s' is not a container port or port range", containerPort)
+	}
+	if hostPort != "" && !validPortRange(hostPort) {
+		return v, fmt.Errorf("'%s' is not a host port or port range", hostPort)
+	}
+
+	switch ip {
+	case "":
+		v.add("(none)", "Host IP", "Listen on every interface of the host, IPv4 and IPv6")
+		local := "127.0.0.1:" + hostPort + ":" + containerPort
+		if hasProtocol {
+			local += "/" + protocol
+		}
+		v.warn("The port is reachable from other machines, and Docker's firewall rules bypass ufw and firewalld. Use %s to keep it local.", local)
+	case "0.0.0.0", "[::]":
+		v.add(ip, "Host IP", "Listen on every interface of the host")
+		v.warn("The port is reachable from other machines, and Docker's firewall rules bypass ufw and firewalld.")
+	case "127.0.0.1", "[::1]":
+		v.add(ip, "Host IP", "Listen on the loopback interface only, so only this machine can connect")
+	default:
+		v.add(ip, "Host IP", "Only connections to this address of the host are forwarded")
+	}
+
+	switch {
+	case hostPort == "" && len(parts) == 2:
+		v.add("(empty)", "Host port", "Docker picks a free port on the host; see it with docker port <container>")
+	case hostPort == "":
+		v.add("(none)", "Host port", "Docker picks a free port on the host; see it with docker port <container>")
+	case strings.Contains(hostPort, "-"):
+		v.add(hostPort, "Host ports", "Docker uses the first free port in this range")
+	default:
+		v.add(hostPort, "Host port", "Connect to this port on the host")
+		if n, _ := strconv.Atoi(hostPort); n < 1024 {
+			v.warn("Ports below 1024 are privileged; rootless Docker cannot publish them without extra setup.")
+		}
+	}
+
+	if strings.Contains(containerPort, "-") {
+		v.add(containerPort, "Container ports", "The ports the application listens on inside the container")
+	} else {
+		v.add(containerPort, "Container port", "The port the application listens on inside the container; it must listen on 0.0.0.0, not 127.0.0.1")
+	}
+
+	switch {
+	case !hasProtocol:
+		v.add("(none)", "Protocol", "TCP, the default")
+	case protocol == "tcp" || protocol == "udp" || protocol == "sctp":
+		v.add(protocol, "Protocol", "Forward "+strings.ToUpper(protocol)+" traffic only; publish the port twice to forward both TCP and UDP")
+	default:
+		return v, fmt.Errorf("'%s' is not a protocol; use tcp, udp or sctp", protocol)
+	}
+	return v, nil
+}
+
+func validPortRange(s string) bool {
+	start, end, isRange := strings.Cut(s, "-")
+	ports := []string{start}
+	if isRange {
+		ports = append(ports, end)
+	}
+	for _, p := range ports {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 || n > 65535 {
+			return false
+		}
+	}
+	return true
+}
+
+var volumeOptions = map[string]string{
+	"ro":         "Read-only: the container cannot change the files",
+	"readonly":   "Read-only: the container cannot change the files",
+	"rw":         "Read-write, the default",
+	"z":          "SELinux: relabel the content so that all containers can share it",
+	"Z":          "SELinux: relabel the content so that only this container can use it",
+	"nocopy":     "Do not copy the image's files at the mount point into an empty volume",
+	"shared":     "Mount propagation: mounts are shared both ways between host and container",
+	"rshared":    "Mount propagation: like shared, including nested mounts",
+	"slave":      "Mount propagation: mounts made on the host appear in the container, not the other way",
+	"rslave":     "Mount propagation: like slave, including nested mounts",
+	"private":    "Mount propagation: no mounts are shared",
+	"rprivate":   "Mount propagation: like private, including nested mounts; the default",
+	"consistent": "Docker Desktop for Mac: ignored by current versions",
+	"cached":     "Docker Desktop for Mac: ignored by current versions",
+	"delegated":  "Docker Desktop for Mac: ignored by current versions",
+}
+
+var volumeNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// parseVolumeFlag parses [source:]destination[:options], where source is a
+// volume name or a host path.
+func parseVolumeFlag(value string) (flagValue, error) {
+	var v flagValue
+	parts := splitVolumeSpec(value)
+	if len(parts) == 0 || len(parts) > 3 {
+		return v, fmt.Errorf("expected [source:]destination[:options]")
+	}
+
+	var source, destination, options string
+	switch len(parts) {
+	case 1:
+		destination = parts[0]
+	case 2:
+		// "/data:ro" is a destination with options, not a bind mount.
+		if isVolumeOptions(parts[1]) && !strings.HasPrefix(parts[1], "/") {
+			destination, options = parts[0], parts[1]
+		} else {
+			source, destination = parts[0], parts[1]
+		}
+	case 3:
+		source, destination, options = parts[0], parts[1], parts[2]
+	}
+
+	switch {
+	case source == "":
+		v.add("(none)", "Source", "An anonymous volume with a random name, kept after the container is removed unless you use --rm")
+	case isHostPath(source):
+		v.add(source, "Host path", "Bind mount: this file or directory on the host appears in the container")
+		if strings.HasPrefix(source, "~") {
+			v.warn("Docker does not expand '~'. Leave it unquoted so the shell expands it, or write $HOME.")
+		}
+		if source == "/var/run/docker.sock" || source == "/run/docker.sock" {
+			v.warn("Mounting the Docker socket gives the container full control of Docker, which is root access to the host.")
+		}
+		if source == "/" || source == "/etc" || source == "/home" || source == "/usr" {
+			v.warn("Mounting %s exposes system files to the container.", source)
+		}
+		v.warn("With -v, a host path that does not exist is created as an empty directory owned by root. --mount type=bind fails instead.")
+	case volumeNamePattern.MatchString(source):
+		v.add(source, "Volume", "Named volume: created on first use and kept until docker volume rm")
+	default:
+		return v, fmt.Errorf("'%s' is neither a volume name nor a host path; host paths must start with /, ./ or ~", source)
+	}
+
+	if !strings.HasPrefix(destination, "/") && !isWindowsPath(destination) {
+		return v, fmt.Errorf("the destination '%s' must be an absolute path inside the container", destination)
+	}
+	v.add(destination, "Container path", "Where the files appear inside the container, hiding anything the image had there")
+
+	if options != "" {
+		for _, o := range strings.Split(options, ",") {
+			meaning, ok := volumeOptions[o]
+			if !ok {
+				return v, fmt.Errorf("'%s' is not a volume option", o)
+			}
+			v.add(o, "Option", meaning)
+			if o == "Z" && isHostPath(source) {
+				v.warn("'Z' relabels the host files for this container only; other processes on the host may lose access. Never use it on system or home directories.")
+			}
+		}
+	}
+	return v, nil
+}
+
+// splitVolumeSpec splits on ':' but keeps Windows drive letters such as
+// C:\data together.
+func splitVolumeSpec(spec string) []string {
+	var parts []string
+	for spec != "" {
+		if isWindowsPath(spec) {
+			end := strings.Index(spec[2:], ":")
+			if end < 0 {
+				return append(parts, spec)
+			}
+			parts = append(parts, spec[:end+2])
+			spec = spec[end+3:]
+			continue
+		}
+		part, rest, ok := strings.Cut(spec, ":")
+		parts = append(parts, part)
+		if !ok {
+			break
+		}
+		spec = rest
+	}
+	return parts
+}
+
+func isWindowsPath(s string) bool {
+	return len(s) >= 3 && s[1] == ':' && (s[2] == '\\' || s[2] == '/') &&
+		(s[0] >= 'a' && s[0] <= 'z' || s[0] >= 'A' && s[0] <= 'Z')
+}
+
+func isHostPath(s string) bool {
+	return strings.HasPrefix(s, "/") || strings.HasPrefix(s, ".") || strings.HasPrefix(s, "~") || isWindowsPath(s)
+}
+
+func isVolumeOptions(s string) bool {
+	for _, o := range strings.Split(s, ",") {
+		if _, ok := volumeOptions[o]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+var mountKeys = map[string]string{
+	"type":             "bind, volume, tmpfs, npipe or cluster; volume is the default",
+	"source":           "The volume name or host path; leave it out for an anonymous volume",
+	"src":              "The volume name or host path; leave it out for an anonymous volume",
+	"destination":      "Where the mount appears inside the container",
+	"dst":              "Where the mount appears inside the container",
+	"target":           "Where the mount appears inside the container",
+	"readonly":         "Mount read-only",
+	"ro":               "Mount read-only",
+	"bind-propagation": "Whether mounts made inside the bind mount are shared with the host",
+	"bind-recursive":   "How submounts of the host path are handled: enabled, disabled, writable or readonly",
+	"consistency":      "Docker Desktop for Mac: ignored by current versions",
+	"volume-driver":    "The volume plugin that creates the volume",
+	"volume-opt":       "An option passed to the volume driver, such as type=nfs",
+	"volume-nocopy":    "Do not copy the image's files at the mount point into an empty volume",
+	"volume-subpath":   "Mount only this directory of the volume",
+	"volume-label":     "A label added to the volume when it is created",
+	"tmpfs-size":       "Maximum size of the tmpfs; unlimited by default",
+	"tmpfs-mode":       "File mode of the tmpfs in octal, e.g. 1770; 1777 by default",
+}
+
+// parseMountFlag parses the comma-separated key=value list of --mount.
+func parseMountFlag(value string) (flagValue, error) {
+	var v flagValue
+	fields := map[string]string{}
+	for _, field := range splitCSV(value) {
+		key, val, _ := strings.Cut(field, "=")
+		meaning, ok := mountKeys[key]
+		if !ok {
+			return v, fmt.Errorf("'%s' is not a --mount key", key)
+		}
+		fields[key] = val
+		label := "Key " + key
+		if val == "" {
+			val = key
+		}
+		if key == "tmpfs-size" {
+			if n, err := parseByteSize(val); err == nil {
+				meaning += fmt.Sprintf(" (%s)", describeBytes(n))
+			}
+		}
+		v.add(val, label, meaning)
+	}
+
+	kind := fields["type"]
+	if kind == "" {
+		kind = "volume"
+	}
+	target := firstNonEmpty(fields["destination"], fields["dst"], fields["target"])
+	source := firstNonEmpty(fields["source"], fields["src"])
+	if target == "" {
+		return v, fmt.Errorf("--mount needs a target, e.g. target=/data")
+	}
+	switch kind {
+	case "bind":
+		if source == "" {
+			return v, fmt.Errorf("a bind mount needs a source on the host")
+		}
+		v.warn("Unlike -v, a bind mount with --mount fails if %s does not exist on the host.", source)
+	case "tmpfs":
+		if source != "" {
+			return v, fmt.Errorf("a tmpfs mount has no source")
+		}
+		v.warn("tmpfs lives in memory and counts toward the container's memory limit; its content is lost when the container stops.")
+	case "volume", "npipe", "cluster":
+	default:
+		return v, fmt.Errorf("'%s' is not a mount type; use bind, volume or tmpfs", kind)
+	}
+	return v, nil
+}
+
+// splitCSV splits on commas outside double quotes, the way docker parses
+// --mount values.
+func splitCSV(s string) []string {
+	var (
+		fields []string
+		b      strings.Builder
+		quoted bool
+	)
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			fields = append(fields, b.String())
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return append(fields, b.String())
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+var byteSizePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?) ?([kKmMgGtTpP])?[iI]?[bB]?$`)
+
+// parseByteSize parses sizes such as 512m or 1.5g the way docker does, with
+// binary units.
+func parseByteSize(s string) (int64, error) {
+	m := byteSizePattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("'%s' is not a size; use a number followed by b, k, m or g, e.g. 512m", s)
+	}
+	n, _ := strconv.ParseFloat(m[1], 64)
+	unit := strings.ToLower(m[2])
+	shift := strings.Index("kmgtp", unit) + 1
+	if unit == "" {
+		shift = 0
+	}
+	return int64(n * math.Pow(1024, float64(shift))), nil
+}
+
+func describeBytes(n int64) string {
+	units := []string{"bytes", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	size := float64(n)
+	i := 0
+	for size >= 1024 && i < len(units)-1 {
+		size /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d bytes", n)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(fmt.Sprintf("%.2f", size), "0"), ".0") + " " + units[i]
+}
+
+// parseSizeFlag splits a byte size such as 512m into its amount and unit.
+// The callers add what the size means for their flag.
+func parseSizeFlag(value string) (flagValue, int64, error) {
+	var v flagValue
+	n, err := parseByteSize(value)
+	if err != nil {
+		return v, 0, err
+	}
+	number := strings.TrimRight(value, "bBiIkKmMgGtTpP ")
+	unit := strings.TrimPrefix(value, number)
+	v.add(number, "Amount", fmt.Sprintf("%d bytes", n))
+	if unit == "" {
+		v.add("(none)", "Unit", "Bytes. Without a unit the number is taken as bytes")
+	} else {
+		v.add(unit, "Unit", "Binary units: k = 1024 bytes, m = 1024 k, g = 1024 m")
+	}
+	return v, n, nil
+}
+
+func parseMemoryFlag(value string) (flagValue, error) {
+	v, n, err := parseSizeFlag(value)
+	if err != nil {
+		return v, err
+	}
+	v.add(describeBytes(n), "Limit", "When the container uses more, the kernel's OOM killer stops a process in it (exit code 137)")
+	if n < 6*1024*1024 {
+		v.warn("Docker rejects memory limits below 6MiB.")
+	}
+	return v, nil
+}
+
+func parseMemoryReservationFlag(value string) (flagValue, error) {
+	v, n, err := parseSizeFlag(value)
+	if err != nil {
+		return v, err
+	}
+	v.add(describeBytes(n), "Soft limit", "The container may use more while memory is plentiful. When the host runs low, the kernel reclaims memory from it until it is back under this amount")
+	v.warn("A reservation does not stop the container from using more memory. Combine it with a higher --memory, which must be larger than the reservation.")
+	return v, nil
+}
+
+func parseShmSizeFlag(value string) (flagValue, error) {
+	v, n, err := parseSizeFlag(value)
+	if err != nil {
+		return v, err
+	}
+	v.add(describeBytes(n), "Size", "Size of the tmpfs mounted at /dev/shm, 64MiB by default. What is stored there counts towards the container's memory")
+	if n < 64*1024*1024 {
+		v.warn("This is smaller than the default of 64MiB. Browsers such as Chrome and databases such as PostgreSQL fail when /dev/shm is too small.")
+	}
+	return v, nil
+}
+
+func parseMemorySwapFlag(value string) (flagValue, error) {
+	if value == "-1" {
+		var v flagValue
+		v.add("-1", "Limit", "Unlimited swap, on top of the --memory limit")
+		return v, nil
+	}
+	v, n, err := parseSizeFlag(value)
+	if err != nil {
+		return v, err
+	}
+	v.add(describeBytes(n), "Limit", "Memory plus swap: with --memory 512m, 1g allows 512MiB of swap. The same value as --memory disables swap")
+	return v, nil
+}
+
+func parseCPUsFlag(value string) (flagValue, error) {
+	var v flagValue
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n <= 0 {
+		return v, fmt.Errorf("'%s' is not a number of CPUs; use a positive number such as 1.5", value)
+	}
+	v.add(value, "CPUs", fmt.Sprintf("At most %s CPUs' worth of time, spread over any of the host's cores", value))
+	v.add(fmt.Sprintf("%d/100000", int64(n*100000)), "Quota/period", "The same as --cpu-quota and --cpu-period: microseconds of CPU time per 100ms")
+	v.warn("This throttles the container rather than reserving CPUs for it. Use --cpuset-cpus to pin it to specific cores.")
+	return v, nil
+}
+
+func parseCPUSetFlag(value string) (flagValue, error) {
+	var v flagValue
+	// Ranges are counted rather than expanded, so 0-99999999 stays cheap.
+	var parts []string
+	count := 0
+	for _, part := range strings.Split(value, ",") {
+		start, end, isRange := strings.Cut(part, "-")
+		a, err := strconv.Atoi(start)
+		if err != nil || a < 0 {
+			return v, fmt.Errorf("'%s' is not a CPU list; use numbers and ranges such as 0-3,5", value)
+		}
+		if !isRange {
+			parts = append(parts, start)
+			count++
+			continue
+		}
+		b, err := strconv.Atoi(end)
+		if err != nil || b < a {
+			return v, fmt.Errorf("'%s' is not a CPU range", part)
+		}
+		parts = append(parts, part)
+		count += b - a + 1
+	}
+	v.add(value, "Cores", fmt.Sprintf("The container only runs on cores %s (%s); core numbers start at 0", strings.Join(parts, ", "), plural(count, "core")))
+	return v, nil
+}
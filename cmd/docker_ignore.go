@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var dockerIgnoreFiles bool
+
+var dockerIgnoreCmd = &cobra.Command{
+	Use:   "ignore [context]",
+	Short: "Explains .dockerignore and what the build context sends to the daemon",
+	Long: `This command reads the .dockerignore file of a build context, explains every
+pattern in it, and walks the context to report which files docker build sends to
+the daemon, how large they are, and which directories take up the most space.
+The context defaults to the current directory.
+For example:
+
+- explain docker ignore
+- explain docker ignore ./app
+- explain docker ignore --files`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		context := "."
+		if len(args) == 1 {
+			context = args[0]
+		}
+		if info, err := os.Stat(context); err != nil || !info.IsDir() {
+			fmt.Printf("'%s' is not a directory. Pass the directory you give to docker build.\n", context)
+			return
+		}
+
+		rules, err := readDockerIgnore(filepath.Join(context, ".dockerignore"))
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Println("Could not read .dockerignore:", err)
+			return
+		}
+		if os.IsNotExist(err) {
+			fmt.Println("No .dockerignore found, so docker build sends everything in the context to the daemon.")
+		} else {
+			printDockerIgnoreRules(rules)
+		}
+
+		report, err := walkBuildContext(context, rules)
+		if err != nil {
+			fmt.Println("Could not read the build context:", err)
+			return
+		}
+		fmt.Print("\n")
+		printBuildContextReport(report, rules)
+		fmt.Print("\n")
+		fmt.Printf("See: %s\n", dockerCommandTopic("build"))
+	},
+}
+
+func init() {
+	dockerCmd.AddCommand(dockerIgnoreCmd)
+
+	dockerIgnoreCmd.Flags().BoolVar(&dockerIgnoreFiles, "files", false, "List every file that is sent to the daemon")
+}
+
+// dockerIgnoreRule is one pattern of a .dockerignore file. Unlike
+// .gitignore, every pattern is relative to the root of the build context.
+type dockerIgnoreRule struct {
+	text    string
+	line    int
+	negate  bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+func readDockerIgnore(file string) ([]dockerIgnoreRule, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []dockerIgnoreRule
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		r := dockerIgnoreRule{text: text, line: n}
+		p := text
+		if strings.HasPrefix(p, "!") {
+			r.negate = true
+			p = strings.TrimSpace(p[1:])
+		}
+		p = strings.TrimPrefix(path.Clean(filepath.ToSlash(p)), "/")
+		if p == "" || p == "." {
+			continue
+		}
+		r.pattern = p
+		r.re = globRegexp(p)
+		rules = append(rules, r)
+	}
+	return rules, scanner.Err()
+}
+
+// matches reports whether the rule matches rel or one of its parent
+// directories, since excluding a directory excludes everything inside it.
+func (r dockerIgnoreRule) matches(rel string) bool {
+	for p := rel; p != "." && p != ""; p = path.Dir(p) {
+		if r.re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// dockerIgnoreDecision returns the index of the last rule matching rel, or
+// -1. The path is excluded when that rule is not a '!' rule.
+func dockerIgnoreDecision(rules []dockerIgnoreRule, rel string) int {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].matches(rel) {
+			return i
+		}
+	}
+	return -1
+}
+
+func describeDockerIgnoreRule(r dockerIgnoreRule) string {
+	target := r.pattern
+	if strings.ContainsAny(r.pattern, "*?[") {
+		target = "paths matching " + r.pattern
+	}
+	where := "at the root of the context"
+	if strings.HasPrefix(r.pattern, "**/") {
+		where = "at any depth"
+	} else if strings.Contains(r.pattern, "/") {
+		where = "relative to the root of the context"
+	}
+	if r.negate {
+		return fmt.Sprintf("Sends %s (%s) again, even if an earlier pattern excluded it.", target, where)
+	}
+	return fmt.Sprintf("Excludes %s %s, including everything inside matching directories.", target, where)
+}
+
+func printDockerIgnoreRules(rules []dockerIgnoreRule) {
+	fmt.Printf(".dockerignore has %s. The last pattern matching a path decides whether it is sent:\n", plural(len(rules), "pattern"))
+	fmt.Print("\n")
+	for _, r := range rules {
+		fmt.Printf("  %-24s %s\n", r.text, describeDockerIgnoreRule(r))
+		if strings.Contains(r.pattern, "**") {
+			fmt.Printf("  %-24s '**' matches any number of directories, including none.\n", "")
+		}
+		if !strings.Contains(r.pattern, "/") && strings.ContainsAny(r.pattern, "*?[") {
+			fmt.Printf("  %-24s !!! Unlike .gitignore, this only matches in the root of the context. Use **/%s to match at any depth.\n", "", r.pattern)
+		}
+	}
+	fmt.Print("\n")
+	fmt.Println("The Dockerfile and .dockerignore are always sent, even if a pattern excludes them.")
+	fmt.Println("With BuildKit, a <Dockerfile>.dockerignore next to the Dockerfile is used instead of this file.")
+}
+
+// buildContextReport sums up what docker build would send to the daemon.
+type buildContextReport struct {
+	files         []string
+	sentSize      int64
+	excludedFiles int
+	excludedSize  int64
+	sentDirs      map[string]int64 // size sent per top-level directory
+	excludedDirs  map[string]int64
+	ruleHits      []int // files decided by each rule
+}
+
+func walkBuildContext(context string, rules []dockerIgnoreRule) (buildContextReport, error) {
+	report := buildContextReport{
+		sentDirs:     map[string]int64{},
+		excludedDirs: map[string]int64{},
+		ruleHits:     make([]int, len(rules)),
+	}
+	err := filepath.WalkDir(context, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(context, p)
+		rel = filepath.ToSlash(rel)
+
+		top := ""
+		if dir, _, ok := strings.Cut(rel, "/"); ok {
+			top = dir + "/"
+		}
+
+		i := dockerIgnoreDecision(rules, rel)
+		if i >= 0 {
+			report.ruleHits[i]++
+		}
+		excluded := i >= 0 && !rules[i].negate && rel != "Dockerfile" && rel != ".dockerignore"
+		if excluded {
+			report.excludedFiles++
+			report.excludedSize += info.Size()
+			if top != "" {
+				report.excludedDirs[top] += info.Size()
+			}
+			return nil
+		}
+		report.files = append(report.files, rel)
+		report.sentSize += info.Size()
+		if top != "" {
+			report.sentDirs[top] += info.Size()
+		}
+		return nil
+	})
+	return report, err
+}
+
+// heavyContextDirs are directories that rarely belong in a build context.
+var heavyContextDirs = map[string]string{
+	".git/":         "the repository history",
+	"node_modules/": "dependencies that the Dockerfile usually installs itself",
+	".venv/":        "a local Python virtual environment",
+	"venv/":         "a local Python virtual environment",
+	"__pycache__/":  "Python bytecode caches",
+	"target/":       "build output",
+	"dist/":         "build output",
+	"build/":        "build output",
+	".terraform/":   "Terraform providers and state",
+}
+
+func printBuildContextReport(report buildContextReport, rules []dockerIgnoreRule) {
+	fmt.Printf("Build context: %s, %s sent to the daemon", plural(len(report.files), "file"), formatSize(report.sentSize))
+	if report.excludedFiles > 0 {
+		fmt.Printf(" (%s, %s excluded)", plural(report.excludedFiles, "file"), formatSize(report.excludedSize))
+	}
+	fmt.Print(".\n")
+
+	if dirs := largestDirs(report.sentDirs, 5); len(dirs) > 0 {
+		fmt.Print("\n")
+		fmt.Println("Largest directories sent:")
+		for _, dir := range dirs {
+			fmt.Printf("  %10s  %s\n", formatSize(report.sentDirs[dir]), dir)
+		}
+		for _, dir := range dirs {
+			if why, ok := heavyContextDirs[dir]; ok {
+				fmt.Printf("!!! %s holds %s and is sent on every build. Add '%s' to .dockerignore unless the build needs it.\n", dir, why, strings.TrimSuffix(dir, "/"))
+			}
+		}
+	}
+	if dirs := largestDirs(report.excludedDirs, 3); len(dirs) > 0 {
+		fmt.Print("\n")
+		fmt.Println("Largest directories excluded:")
+		for _, dir := range dirs {
+			fmt.Printf("  %10s  %s\n", formatSize(report.excludedDirs[dir]), dir)
+		}
+	}
+
+	var unused []string
+	for i, r := range rules {
+		if report.ruleHits[i] == 0 {
+			unused = append(unused, r.text)
+		}
+	}
+	if len(unused) > 0 {
+		fmt.Print("\n")
+		fmt.Printf("Patterns that match nothing in this context: %s\n", strings.Join(unused, ", "))
+	}
+
+	if dockerIgnoreFiles {
+		fmt.Print("\n")
+		fmt.Println("Files sent to the daemon:")
+		for _, f := range report.files {
+			fmt.Printf("  %s\n", f)
+		}
+	}
+}
+
+func largestDirs(sizes map[string]int64, n int) []string {
+	dirs := make([]string, 0, len(sizes))
+	for dir := range sizes {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if sizes[dirs[i]] != sizes[dirs[j]] {
+			return sizes[dirs[i]] > sizes[dirs[j]]
+		}
+		return dirs[i] < dirs[j]
+	})
+	if len(dirs) > n {
+		dirs = dirs[:n]
+	}
+	return dirs
+}
+
+// formatSize prints a byte count the way docker does, e.g. "12.3MB".
+func formatSize(n int64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	size := float64(n)
+	i := 0
+	for size >= 1000 && i < len(units)-1 {
+		size /= 1000
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%dB", n)
+	}
+	return fmt.Sprintf("%.1f%s", size, units[i])
+}
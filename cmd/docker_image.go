@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"regexp"
+	"strings"
+)
+
+var dockerImageCmd = &cobra.Command{
+	Use:   "image <reference>",
+	Short: "Explains the parts of an image reference and which registry it points to",
+	Long: `This command splits an image reference into registry, namespace, repository, tag
+and digest, fills in the defaults Docker uses, and explains which server docker
+pull and docker push contact for it.
+For example:
+
+- explain docker image nginx
+- explain docker image ghcr.io/owner/app:1.2
+- explain docker image localhost:5000/team/api@sha256:<digest>`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ref, err := parseImageReference(args[0])
+		if err != nil {
+			fmt.Printf("'%s' is not a valid image reference: %v\n", args[0], err)
+			return
+		}
+		printImageReference(ref)
+	},
+}
+
+func init() {
+	dockerCmd.AddCommand(dockerImageCmd)
+}
+
+const defaultRegistry = "docker.io"
+
+// imageReference is a parsed [registry/][namespace/]repository[:tag][@digest]
+// reference, with the parts that were left out marked as defaulted.
+type imageReference struct {
+	original         string
+	registry         string
+	namespace        string
+	repository       string
+	tag              string
+	digest           string
+	defaultRegistry  bool
+	defaultNamespace bool
+	defaultTag       bool
+}
+
+var (
+	imagePathComponent = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+	imageTagPattern    = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	imageDigestPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}$`)
+	imageHostPattern   = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*|\[[0-9a-fA-F:]+\])(?::[0-9]+)?$`)
+)
+
+// parseImageReference follows the grammar of the distribution reference
+// package that Docker uses.
+func parseImageReference(s string) (imageReference, error) {
+	ref := imageReference{original: s}
+	if s == "" {
+		return ref, fmt.Errorf("the reference is empty")
+	}
+
+	name := s
+	if at := strings.Index(name, "@"); at >= 0 {
+		name, ref.digest = name[:at], name[at+1:]
+		if !imageDigestPattern.MatchString(ref.digest) {
+			return ref, fmt.Errorf("'%s' is not a digest; it should look like sha256:<64 hex characters>", ref.digest)
+		}
+	}
+	// A colon after the last slash starts the tag; earlier ones belong to
+	// the registry's port.
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		name, ref.tag = name[:colon], name[colon+1:]
+		if !imageTagPattern.MatchString(ref.tag) {
+			return ref, fmt.Errorf("'%s' is not a valid tag; tags use letters, digits, '_', '.' and '-', up to 128 characters", ref.tag)
+		}
+	}
+
+	components := strings.Split(name, "/")
+	if len(components) > 1 && (strings.ContainsAny(components[0], ".:") || components[0] == "localhost" || strings.ToLower(components[0]) != components[0]) {
+		ref.registry = components[0]
+		components = components[1:]
+		if !imageHostPattern.MatchString(ref.registry) {
+			return ref, fmt.Errorf("'%s' is not a registry host name", ref.registry)
+		}
+	}
+	if ref.registry == "" || ref.registry == "index.docker.io" {
+		ref.defaultRegistry = ref.registry == ""
+		ref.registry = defaultRegistry
+	}
+	for _, c := range components {
+		if !imagePathComponent.MatchString(c) {
+			if strings.ToLower(c) == c {
+				return ref, fmt.Errorf("'%s' is not a valid name component; use lowercase letters, digits and single separators '.', '_', '__' or '-'", c)
+			}
+			return ref, fmt.Errorf("'%s' contains uppercase letters; repository names must be lowercase", c)
+		}
+	}
+	if len(name) > 255 {
+		return ref, fmt.Errorf("the name is longer than 255 characters")
+	}
+
+	ref.repository = components[len(components)-1]
+	ref.namespace = strings.Join(components[:len(components)-1], "/")
+	if ref.registry == defaultRegistry && ref.namespace == "" {
+		ref.namespace = "library"
+		ref.defaultNamespace = true
+	}
+	if ref.tag == "" && ref.digest == "" {
+		ref.tag = "latest"
+		ref.defaultTag = true
+	}
+	return ref, nil
+}
+
+// path is the repository path on the registry, e.g. library/nginx.
+func (r imageReference) path() string {
+	if r.namespace == "" {
+		return r.repository
+	}
+	return r.namespace + "/" + r.repository
+}
+
+// String returns the fully qualified reference Docker resolves r to.
+func (r imageReference) String() string {
+	s := r.registry + "/" + r.path()
+	if r.tag != "" {
+		s += ":" + r.tag
+	}
+	if r.digest != "" {
+		s += "@" + r.digest
+	}
+	return s
+}
+
+// apiHost is the server that implements the registry API for r.
+func (r imageReference) apiHost() string {
+	if r.registry == defaultRegistry {
+		return "registry-1.docker.io"
+	}
+	return r.registry
+}
+
+func printImageReference(r imageReference) {
+	fmt.Printf("%s resolves to %s\n", r.original, r)
+	fmt.Print("\n")
+
+	var v flagValue
+	if r.defaultRegistry {
+		v.add("(none)", "Registry", "Docker Hub (docker.io), the default when the first part has no '.' or ':' and is not localhost")
+	} else if r.registry == defaultRegistry {
+		v.add(r.registry, "Registry", "Docker Hub")
+	} else {
+		v.add(r.registry, "Registry", "The registry server, with an optional port")
+	}
+
+	switch {
+	case r.defaultNamespace:
+		v.add("(none)", "Namespace", "library, where Docker Hub keeps its official images")
+	case r.namespace == "":
+		v.add("(none)", "Namespace", "The repository sits at the top level of the registry")
+	default:
+		v.add(r.namespace, "Namespace", "The user, organisation or project that owns the repository")
+	}
+	v.add(r.repository, "Repository", "The image's name within the namespace")
+
+	switch {
+	case r.defaultTag:
+		v.add("(none)", "Tag", "latest, the default. It is just a name, not necessarily the newest image")
+	case r.tag != "" && r.digest != "":
+		v.add(r.tag, "Tag", "Ignored for pulling because a digest is given; kept for readability")
+	case r.tag != "":
+		v.add(r.tag, "Tag", "A movable name: the same tag can point to a different image tomorrow")
+	}
+	if r.digest != "" {
+		v.add(shortDigest(r.digest), "Digest", "The exact content hash of the manifest: always the same image, even if tags move")
+	}
+
+	if r.tag == "latest" && r.digest == "" {
+		v.warn("'latest' can change between pulls. Pin a version tag or a digest for reproducible builds and deployments.")
+	}
+	printFlagValue(v, "  ")
+
+	fmt.Print("\n")
+	fmt.Println("docker pull and docker push contact:")
+	fmt.Printf("  https://%s/v2/%s/manifests/%s\n", r.apiHost(), r.path(), firstNonEmpty(r.digest, r.tag))
+	switch {
+	case r.registry == defaultRegistry:
+		fmt.Println("  Tokens come from auth.docker.io. Anonymous pulls are rate limited; docker login raises the limit.")
+	case isLocalRegistry(r.registry):
+		fmt.Println("  Registries on localhost may use plain HTTP; Docker allows it for 127.0.0.0/8 without extra configuration.")
+	default:
+		fmt.Println("  Docker uses HTTPS. A registry without a trusted certificate must be listed in insecure-registries in daemon.json.")
+	}
+	login := "docker login"
+	if r.registry != defaultRegistry {
+		login += " " + r.registry
+	}
+	fmt.Printf("  Credentials come from: %s\n", login)
+
+	fmt.Print("\n")
+	switch {
+	case r.registry == defaultRegistry && r.namespace == "library":
+		fmt.Println("Pushing: only Docker maintains official images in library/. Tag the image under your own namespace first:")
+		fmt.Printf("$ docker tag %s <your-user>/%s\n", r.original, r.repository)
+	case r.registry == defaultRegistry:
+		fmt.Printf("Pushing requires being logged in to Docker Hub as %s, or as a member of that organisation.\n", strings.Split(r.namespace, "/")[0])
+	default:
+		fmt.Printf("Pushing requires write access to %s on %s.\n", r.path(), r.registry)
+	}
+	if r.digest != "" {
+		fmt.Println("A reference with a digest cannot be pushed; push a tag and Docker reports the digest it got.")
+	}
+
+	fmt.Print("\n")
+	fmt.Printf("See: %s\n", dockerCommandTopic("push"))
+}
+
+func isLocalRegistry(host string) bool {
+	h := host
+	if i := strings.LastIndex(h, ":"); i >= 0 && !strings.HasSuffix(h, "]") {
+		h = h[:i]
+	}
+	return h == "localhost" || strings.HasPrefix(h, "127.") || h == "[::1]"
+}
+
+func shortDigest(d string) string {
+	algorithm, hex, _ := strings.Cut(d, ":")
+	if len(hex) > 12 {
+		hex = hex[:12] + "..."
+	}
+	return algorithm + ":" + hex
+}
@@ -0,0 +1,536 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var dockerImageTarCmd = &cobra.Command{
+	Use:   "image-tar <file>",
+	Short: "Analyzes the layers of an image saved with docker save",
+	Long: `This command reads an image archive written by docker save, or an OCI image
+layout packed into a tar file, without needing a Docker daemon. It lists each
+layer with the Dockerfile instruction that produced it, its size and the largest
+files it adds, and points out instructions that make the image bigger than it
+needs to be.
+For example:
+
+- docker save -o app.tar my-app:latest && explain docker image-tar app.tar
+- explain docker image-tar oci-layout.tar`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		archive, err := readImageArchive(args[0])
+		if err != nil {
+			fmt.Println("Could not read the image archive:", err)
+			return
+		}
+		if len(archive.images) == 0 {
+			fmt.Printf("%s contains no image manifest (manifest.json or index.json).\n", args[0])
+			return
+		}
+		if err := archive.analyzeLayers(); err != nil {
+			fmt.Println("Could not read the layers:", err)
+			return
+		}
+		fmt.Printf("%s: %s archive with %s\n", args[0], archive.format, plural(len(archive.images), "image"))
+		for _, img := range archive.images {
+			fmt.Print("\n")
+			printImageLayers(archive, img)
+		}
+		fmt.Print("\n")
+		printLayerCaching()
+	},
+}
+
+func init() {
+	dockerCmd.AddCommand(dockerImageTarCmd)
+}
+
+// smallArchiveEntry is the largest entry kept in memory on the first pass;
+// manifests and configs are far smaller, layers usually far larger.
+const smallArchiveEntry = 4 << 20
+
+// imageArchive is a docker save or OCI layout tar file.
+type imageArchive struct {
+	path   string
+	format string
+	small  map[string][]byte // entries up to smallArchiveEntry bytes
+	images []*archivedImage
+	layers map[string]*layerReport // by entry name
+}
+
+type archivedImage struct {
+	tags   []string
+	config imageConfig
+	layers []string // entry names, bottom first
+}
+
+type imageConfig struct {
+	Architecture string    `json:"architecture"`
+	OS           string    `json:"os"`
+	Created      time.Time `json:"created"`
+	History      []struct {
+		CreatedBy  string `json:"created_by"`
+		EmptyLayer bool   `json:"empty_layer"`
+		Comment    string `json:"comment"`
+	} `json:"history"`
+}
+
+// layerReport describes the files in one layer.
+type layerReport struct {
+	stored    int64 // size of the layer in the archive, possibly compressed
+	size      int64 // total size of the files it adds
+	files     int
+	largest   []layerFile
+	paths     map[string]int64
+	whiteouts []string // paths the layer deletes from the layers below
+	err       string
+	seen      bool // the archive contains the layer
+}
+
+type layerFile struct {
+	path string
+	size int64
+}
+
+// openArchive opens a tar file, decompressing it if it is gzipped.
+func openArchive(name string) (*tar.Reader, io.Closer, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := bufio.NewReader(f)
+	if magic, _ := r.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return tar.NewReader(gz), f, nil
+	}
+	return tar.NewReader(r), f, nil
+}
+
+func readImageArchive(name string) (*imageArchive, error) {
+	tr, closer, err := openArchive(name)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	a := &imageArchive{path: name, small: map[string][]byte{}, layers: map[string]*layerReport{}}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("not a tar file: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > smallArchiveEntry {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		a.small[path.Clean(hdr.Name)] = data
+	}
+
+	switch {
+	case a.small["manifest.json"] != nil:
+		a.format = "docker save"
+		if a.small["index.json"] != nil {
+			a.format = "docker save (OCI layout)"
+		}
+		err = a.readDockerManifest()
+	case a.small["index.json"] != nil:
+		a.format = "OCI layout"
+		err = a.readOCIIndex()
+	}
+	return a, err
+}
+
+// readDockerManifest reads the manifest.json that docker save writes.
+func (a *imageArchive) readDockerManifest() error {
+	var manifest []struct {
+		Config   string
+		RepoTags []string
+		Layers   []string
+	}
+	if err := json.Unmarshal(a.small["manifest.json"], &manifest); err != nil {
+		return fmt.Errorf("manifest.json: %w", err)
+	}
+	for _, m := range manifest {
+		img := &archivedImage{tags: m.RepoTags, layers: m.Layers}
+		if err := json.Unmarshal(a.small[path.Clean(m.Config)], &img.config); err != nil {
+			return fmt.Errorf("the image config %s: %w", m.Config, err)
+		}
+		a.images = append(a.images, img)
+	}
+	return nil
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+	Platform    *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform"`
+}
+
+func ociBlob(digest string) string {
+	return "blobs/" + strings.Replace(digest, ":", "/", 1)
+}
+
+// readOCIIndex follows index.json to the image manifests of an OCI layout,
+// descending into nested indexes for multi-platform images.
+func (a *imageArchive) readOCIIndex() error {
+	var walk func(data []byte, tags []string, depth int) error
+	walk = func(data []byte, tags []string, depth int) error {
+		var doc struct {
+			MediaType string          `json:"mediaType"`
+			Manifests []ociDescriptor `json:"manifests"`
+			Config    ociDescriptor   `json:"config"`
+			Layers    []ociDescriptor `json:"layers"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		if doc.Config.Digest != "" {
+			img := &archivedImage{tags: tags}
+			for _, l := range doc.Layers {
+				img.layers = append(img.layers, ociBlob(l.Digest))
+			}
+			if err := json.Unmarshal(a.small[ociBlob(doc.Config.Digest)], &img.config); err != nil {
+				return fmt.Errorf("the image config %s: %w", shortDigest(doc.Config.Digest), err)
+			}
+			a.images = append(a.images, img)
+			return nil
+		}
+		if depth > 3 {
+			return fmt.Errorf("the index is nested too deeply")
+		}
+		for _, m := range doc.Manifests {
+			// Attestation manifests of buildx describe the image, not a platform.
+			if m.Platform != nil && m.Platform.OS == "unknown" {
+				continue
+			}
+			child := a.small[ociBlob(m.Digest)]
+			if child == nil {
+				continue
+			}
+			names := tags
+			if ref := m.Annotations["io.containerd.image.name"]; ref != "" {
+				names = []string{ref}
+			} else if ref := m.Annotations["org.opencontainers.image.ref.name"]; ref != "" && len(tags) == 0 {
+				names = []string{ref}
+			}
+			if err := walk(child, names, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(a.small["index.json"], nil, 0)
+}
+
+// analyzeLayers reads the archive a second time and lists the files in
+// every layer the images use.
+func (a *imageArchive) analyzeLayers() error {
+	for _, img := range a.images {
+		for _, l := range img.layers {
+			a.layers[path.Clean(l)] = &layerReport{paths: map[string]int64{}}
+		}
+	}
+
+	tr, closer, err := openArchive(a.path)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	// docker save stores a layer that two images share once and links the
+	// second entry to it.
+	links := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(hdr.Name)
+		report, ok := a.layers[name]
+		if ok && hdr.Typeflag == tar.TypeSymlink {
+			target := path.Join(path.Dir(name), hdr.Linkname)
+			links[name] = target
+			if _, ok := a.layers[target]; !ok {
+				a.layers[target] = &layerReport{paths: map[string]int64{}}
+			}
+		}
+		if !ok || hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		report.seen = true
+		report.stored = hdr.Size
+		if err := report.read(tr); err != nil {
+			report.err = err.Error()
+		}
+	}
+	for name, target := range links {
+		if report, ok := a.layers[target]; ok && report.seen {
+			a.layers[name] = report
+		}
+	}
+	return nil
+}
+
+func (l *layerReport) read(r io.Reader) error {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+	var content io.Reader = br
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		content = gz
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return fmt.Errorf("the layer is zstd-compressed, which explain cannot read")
+	}
+
+	tr := tar.NewReader(content)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := strings.TrimPrefix(path.Clean(hdr.Name), "./")
+		dir, base := path.Split(name)
+		if deleted, ok := strings.CutPrefix(base, ".wh."); ok {
+			if deleted == ".wh..opq" {
+				l.whiteouts = append(l.whiteouts, dir)
+			} else {
+				l.whiteouts = append(l.whiteouts, dir+deleted)
+			}
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		l.files++
+		l.size += hdr.Size
+		l.paths[name] = hdr.Size
+		l.largest = append(l.largest, layerFile{name, hdr.Size})
+		sort.Slice(l.largest, func(i, j int) bool { return l.largest[i].size > l.largest[j].size })
+		if len(l.largest) > 3 {
+			l.largest = l.largest[:3]
+		}
+	}
+}
+
+var (
+	nopPrefix      = regexp.MustCompile(`^/bin/sh -c #\(nop\)\s*`)
+	shellPrefix    = regexp.MustCompile(`^/bin/sh -c `)
+	buildkitSuffix = regexp.MustCompile(`\s*# buildkit$`)
+	recursiveChown = regexp.MustCompile(`\bch(own|mod)\s+-R\b`)
+)
+
+// dockerfileInstruction turns a history entry's created_by back into the
+// Dockerfile instruction that produced it.
+func dockerfileInstruction(createdBy string) string {
+	s := buildkitSuffix.ReplaceAllString(createdBy, "")
+	if nop := nopPrefix.ReplaceAllString(s, ""); nop != s {
+		return strings.TrimSpace(nop)
+	}
+	if shellPrefix.MatchString(s) {
+		return "RUN " + shellPrefix.ReplaceAllString(s, "")
+	}
+	if run, ok := strings.CutPrefix(s, "RUN "); ok {
+		return "RUN " + shellPrefix.ReplaceAllString(run, "")
+	}
+	if s == "" {
+		return "(unknown instruction)"
+	}
+	return s
+}
+
+// layerBloat lists reasons a layer is larger than it needs to be.
+func layerBloat(instruction string, l *layerReport) []string {
+	var notes []string
+	has := func(prefix string) int64 {
+		var total int64
+		for p, size := range l.paths {
+			if strings.HasPrefix(p, prefix) {
+				total += size
+			}
+		}
+		return total
+	}
+	caches := []struct{ prefix, what, fix string }{
+		{"var/lib/apt/lists/", "apt package lists", "end the same RUN with: rm -rf /var/lib/apt/lists/*"},
+		{"var/cache/apt/archives/", "downloaded .deb packages", "end the same RUN with: apt-get clean"},
+		{"var/cache/apk/", "the apk cache", "use apk add --no-cache"},
+		{"var/cache/dnf/", "the dnf cache", "end the same RUN with: dnf clean all"},
+		{"var/cache/yum/", "the yum cache", "end the same RUN with: yum clean all"},
+		{"root/.cache/pip/", "the pip cache", "use pip install --no-cache-dir"},
+		{"root/.npm/", "the npm cache", "end the same RUN with: npm cache clean --force"},
+		{"usr/local/share/.cache/yarn/", "the yarn cache", "end the same RUN with: yarn cache clean"},
+		{"root/.cache/go-build/", "the Go build cache", "use a multi-stage build or a cache mount"},
+	}
+	for _, c := range caches {
+		if size := has(c.prefix); size > 0 {
+			notes = append(notes, fmt.Sprintf("%s of %s stay in this layer; %s.", formatSize(size), c.what, c.fix))
+		}
+	}
+	if strings.HasPrefix(instruction, "COPY") || strings.HasPrefix(instruction, "ADD") {
+		for dir, why := range heavyContextDirs {
+			for p := range l.paths {
+				if strings.HasPrefix(p, dir) || strings.Contains(p, "/"+dir) {
+					notes = append(notes, fmt.Sprintf("It copies %s, %s. Add %s to .dockerignore.", dir, why, strings.TrimSuffix(dir, "/")))
+					break
+				}
+			}
+		}
+	}
+	if strings.HasPrefix(instruction, "RUN") && recursiveChown.MatchString(instruction) {
+		notes = append(notes, "chown -R or chmod -R copies every file it touches into a new layer; use COPY --chown or --chmod instead.")
+	}
+	return notes
+}
+
+func printImageLayers(a *imageArchive, img *archivedImage) {
+	name := firstNonEmpty(strings.Join(img.tags, ", "), "<untagged>")
+	fmt.Printf("Image %s, %s/%s", name, img.config.OS, img.config.Architecture)
+	if !img.config.Created.IsZero() {
+		fmt.Printf(", built %s", timeAgo(img.config.Created))
+	}
+	fmt.Print("\n")
+	fmt.Print("\n")
+
+	// History entries with empty_layer set describe instructions such as
+	// ENV or CMD that only change the configuration.
+	var instructions, metadata []string
+	for _, h := range img.config.History {
+		if h.EmptyLayer {
+			metadata = append(metadata, dockerfileInstruction(h.CreatedBy))
+		} else {
+			instructions = append(instructions, dockerfileInstruction(h.CreatedBy))
+		}
+	}
+
+	var total, stored int64
+	addedAt := map[string]int{}
+	var notes []string
+	fmt.Println("Layers, from the base image up:")
+	for i, entry := range img.layers {
+		l := a.layers[path.Clean(entry)]
+		instruction := "(no history entry)"
+		if i < len(instructions) {
+			instruction = instructions[i]
+		}
+		if len(instruction) > 90 {
+			instruction = instruction[:87] + "..."
+		}
+		if !l.seen {
+			fmt.Printf("  %2d  %8s  %s\n", i+1, "?", instruction)
+			fmt.Println("      !!! layer missing from the archive")
+			continue
+		}
+		fmt.Printf("  %2d  %8s  %s\n", i+1, formatSize(l.size), instruction)
+		if l.err != "" {
+			fmt.Printf("      !!! %s\n", l.err)
+			continue
+		}
+		total += l.size
+		stored += l.stored
+		var top []string
+		for _, f := range l.largest {
+			top = append(top, fmt.Sprintf("%s (%s)", f.path, formatSize(f.size)))
+		}
+		if len(top) > 0 {
+			fmt.Printf("      %s, largest: %s\n", plural(l.files, "file"), strings.Join(top, ", "))
+		}
+		for _, w := range l.whiteouts {
+			var wasted int64
+			from := 0
+			for p, size := range img.pathsBelow(a, i) {
+				if p == w || strings.HasPrefix(p, strings.TrimSuffix(w, "/")+"/") {
+					wasted += size
+					if from == 0 || addedAt[p] < from {
+						from = addedAt[p]
+					}
+				}
+			}
+			if wasted > 0 {
+				notes = append(notes, fmt.Sprintf("Layer %d deletes %s, but the %s it took up since layer %d are still in the image. Remove files in the same RUN that creates them.", i+1, w, formatSize(wasted), from))
+			}
+		}
+		for p := range l.paths {
+			if _, ok := addedAt[p]; !ok {
+				addedAt[p] = i + 1
+			}
+		}
+		for _, n := range layerBloat(instruction, l) {
+			notes = append(notes, fmt.Sprintf("Layer %d: %s", i+1, n))
+		}
+	}
+	fmt.Printf("  Total: %s in %s, %s as stored in the archive\n", formatSize(total), plural(len(img.layers), "layer"), formatSize(stored))
+	if len(metadata) > 0 {
+		fmt.Print("\n")
+		fmt.Printf("%s only changed the configuration and added no layer:\n", plural(len(metadata), "instruction"))
+		for _, m := range metadata {
+			fmt.Printf("  %s\n", m)
+		}
+	}
+
+	if len(notes) > 0 {
+		fmt.Print("\n")
+		for _, n := range notes {
+			fmt.Printf("!!! %s\n", n)
+		}
+	}
+}
+
+// pathsBelow collects the files the layers under layer i add.
+func (img *archivedImage) pathsBelow(a *imageArchive, i int) map[string]int64 {
+	paths := map[string]int64{}
+	for _, entry := range img.layers[:i] {
+		for p, size := range a.layers[path.Clean(entry)].paths {
+			paths[p] = size
+		}
+	}
+	return paths
+}
+
+func printLayerCaching() {
+	fmt.Println("How layers and the build cache work:")
+	fmt.Println("- Each RUN, COPY and ADD adds a layer with the files it changed. Layers only ever add: deleting a file in a")
+	fmt.Println("  later layer hides it but does not make the image smaller.")
+	fmt.Println("- docker build reuses a layer from its cache while the instruction, and for COPY and ADD the files it copies,")
+	fmt.Println("  are unchanged. After the first changed layer, every layer above it is rebuilt.")
+	fmt.Println("- So put steps that rarely change (installing packages and dependencies) before steps that change often")
+	fmt.Println("  (COPY . .), and clean up caches in the same RUN that creates them.")
+	fmt.Println("- Multi-stage builds keep compilers and build caches out of the final image entirely.")
+	fmt.Print("\n")
+	fmt.Printf("See: %s\n", dockerCommandTopic("build"))
+}
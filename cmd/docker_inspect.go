@@ -0,0 +1,580 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var dockerInspectCmd = &cobra.Command{
+	Use:   "inspect [file]",
+	Short: "Annotates the JSON output of docker inspect",
+	Long: `This command reads the JSON that docker inspect prints for a container, image,
+network or volume and explains the fields that matter most: the state and exit
+code, what the container runs (Entrypoint and Cmd), published ports, mounts,
+networks and resource limits. The JSON can be piped in, pasted, or read from
+a file.
+For example:
+
+- docker inspect my-container | explain docker inspect
+- docker image inspect nginx | explain docker inspect
+- explain docker inspect network.json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var data []byte
+		var err error
+		switch {
+		case len(args) == 1 && args[0] != "-":
+			data, err = os.ReadFile(args[0])
+		case stdinIsPiped():
+			data, err = io.ReadAll(os.Stdin)
+		default:
+			fmt.Println("Paste the output of docker inspect, then press Ctrl-D on a new line:")
+			data, err = io.ReadAll(os.Stdin)
+			fmt.Print("\n")
+		}
+		if err != nil {
+			fmt.Println("Could not read the JSON:", err)
+			return
+		}
+
+		objects, err := parseInspectJSON(data)
+		if err != nil {
+			fmt.Println("This is not docker inspect output:", err)
+			return
+		}
+		for i, obj := range objects {
+			if i > 0 {
+				fmt.Print("\n")
+			}
+			printInspectObject(obj)
+		}
+	},
+}
+
+func init() {
+	dockerCmd.AddCommand(dockerInspectCmd)
+}
+
+// inspectObject is one element of the array docker inspect prints.
+type inspectObject map[string]any
+
+// parseInspectJSON accepts the array docker inspect prints, or a single
+// object as printed with --format '{{json .}}'.
+func parseInspectJSON(data []byte) ([]inspectObject, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "{") {
+		var obj inspectObject
+		if err := json.Unmarshal([]byte(trimmed), &obj); err != nil {
+			return nil, err
+		}
+		return []inspectObject{obj}, nil
+	}
+	var objects []inspectObject
+	if err := json.Unmarshal([]byte(trimmed), &objects); err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("the array is empty; docker inspect found no object with that name")
+	}
+	return objects, nil
+}
+
+// get looks up a dotted path such as "State.Status".
+func (o inspectObject) get(path string) any {
+	var v any = map[string]any(o)
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+func (o inspectObject) str(path string) string {
+	s, _ := o.get(path).(string)
+	return s
+}
+
+func (o inspectObject) num(path string) int64 {
+	n, _ := o.get(path).(float64)
+	return int64(n)
+}
+
+func (o inspectObject) flag(path string) bool {
+	b, _ := o.get(path).(bool)
+	return b
+}
+
+func (o inspectObject) list(path string) []string {
+	list, _ := o.get(path).([]any)
+	var out []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (o inspectObject) object(path string) map[string]any {
+	m, _ := o.get(path).(map[string]any)
+	return m
+}
+
+// kind tells containers, images, networks and volumes apart by the fields
+// only they have.
+func (o inspectObject) kind() string {
+	switch {
+	case o.get("State") != nil && o.get("HostConfig") != nil:
+		return "container"
+	case o.get("RootFS") != nil || o.get("RepoTags") != nil:
+		return "image"
+	case o.get("IPAM") != nil:
+		return "network"
+	case o.get("Mountpoint") != nil:
+		return "volume"
+	}
+	return ""
+}
+
+func printInspectObject(o inspectObject) {
+	switch o.kind() {
+	case "container":
+		printInspectContainer(o)
+	case "image":
+		printInspectImage(o)
+	case "network":
+		printInspectNetwork(o)
+	case "volume":
+		printInspectVolume(o)
+	default:
+		fmt.Println("This object is not a container, image, network or volume; explain docker inspect does not know its fields.")
+	}
+}
+
+// inspectField prints a field, its value and, below the value, what it
+// means.
+func inspectField(name, value string, meaning ...string) {
+	if len(name) > 16 {
+		fmt.Printf("  %s\n", name)
+		name = ""
+	}
+	fmt.Printf("  %-16s %s\n", name, value)
+	for _, m := range meaning {
+		fmt.Printf("  %-16s %s\n", "", m)
+	}
+}
+
+func inspectSection(title string) {
+	fmt.Print("\n")
+	fmt.Println(title)
+}
+
+func printInspectContainer(o inspectObject) {
+	name := strings.TrimPrefix(o.str("Name"), "/")
+	fmt.Printf("Container %s (%s), created from image %s\n", name, shortID(o.str("Id")), o.str("Config.Image"))
+
+	inspectSection("State")
+	status := o.str("State.Status")
+	started, finished := inspectTime(o.str("State.StartedAt")), inspectTime(o.str("State.FinishedAt"))
+	switch status {
+	case "running":
+		inspectField("Status", status, fmt.Sprintf("The main process (PID %d on the host) has been running since %s.", o.num("State.Pid"), timeAgo(started)))
+	case "exited":
+		inspectField("Status", status, fmt.Sprintf("The main process stopped %s.", timeAgo(finished)))
+	case "created":
+		inspectField("Status", status, "The container was created but never started.")
+	case "paused":
+		inspectField("Status", status, "Its processes are frozen by docker pause; docker unpause resumes them.")
+	case "restarting":
+		inspectField("Status", status, "Docker is restarting it because of its restart policy; it may be crashing in a loop.")
+	default:
+		inspectField("Status", status)
+	}
+	if status != "running" && status != "created" {
+		inspectField("ExitCode", fmt.Sprint(o.num("State.ExitCode")), describeExitCode(o.num("State.ExitCode")))
+	}
+	if o.flag("State.OOMKilled") {
+		inspectField("OOMKilled", "true", "!!! The kernel killed it for using more memory than allowed. Raise --memory or find the leak.")
+	}
+	if msg := o.str("State.Error"); msg != "" {
+		inspectField("Error", msg, "Docker could not start or run the container.")
+	}
+	if n := o.num("RestartCount"); n > 0 {
+		inspectField("RestartCount", fmt.Sprint(n), "How often the restart policy has restarted it. A growing number means it keeps crashing.")
+	}
+	if health := o.str("State.Health.Status"); health != "" {
+		meaning := map[string]string{
+			"healthy":   "The HEALTHCHECK command succeeds.",
+			"unhealthy": "!!! The HEALTHCHECK command keeps failing; see State.Health.Log for its output.",
+			"starting":  "The first health checks have not finished yet.",
+		}[health]
+		inspectField("Health", health, meaning)
+	}
+
+	inspectSection("What it runs (Config)")
+	entrypoint, command := o.list("Config.Entrypoint"), o.list("Config.Cmd")
+	inspectField("Entrypoint", inspectList(entrypoint), "The program that is started. --entrypoint replaces it.")
+	inspectField("Cmd", inspectList(command), "Arguments passed to the entrypoint, or the command itself if there is none. Arguments after the image name in docker run replace it.")
+	if full := append(append([]string{}, entrypoint...), command...); len(full) > 0 {
+		inspectField("Together", strings.Join(quoteWords(full), " "), "The command line the container's main process runs.")
+	}
+	user := o.str("Config.User")
+	if user == "" {
+		inspectField("User", "(empty)", "Runs as root inside the container. Set USER in the Dockerfile or --user to drop privileges.")
+	} else {
+		inspectField("User", user)
+	}
+	if dir := o.str("Config.WorkingDir"); dir != "" {
+		inspectField("WorkingDir", dir, "The directory the command starts in.")
+	}
+	printInspectEnv(o.list("Config.Env"))
+
+	inspectSection("Ports (NetworkSettings.Ports)")
+	ports := o.object("NetworkSettings.Ports")
+	if len(ports) == 0 {
+		ports = o.object("HostConfig.PortBindings")
+	}
+	printInspectPorts(ports)
+
+	inspectSection("Networks")
+	networkMode := o.str("HostConfig.NetworkMode")
+	switch networkMode {
+	case "host":
+		inspectField("NetworkMode", networkMode, "Shares the host's network stack: no isolation, and -p has no effect.")
+	case "none":
+		inspectField("NetworkMode", networkMode, "No network except loopback.")
+	default:
+		inspectField("NetworkMode", networkMode)
+	}
+	networks := o.object("NetworkSettings.Networks")
+	for _, network := range sortedKeys(networks) {
+		settings, _ := networks[network].(map[string]any)
+		n := inspectObject(settings)
+		detail := fmt.Sprintf("IP %s, gateway %s", firstNonEmpty(n.str("IPAddress"), "(none)"), firstNonEmpty(n.str("Gateway"), "(none)"))
+		// Docker resolves the container name on every user-defined network,
+		// and the aliases only on the network they were given for.
+		names := []string{name}
+		for _, alias := range n.list("Aliases") {
+			if alias != name {
+				names = append(names, alias)
+			}
+		}
+		meaning := "Other containers on this network reach it by IP or by the names " + strings.Join(names, ", ")
+		if len(names) == 1 {
+			meaning = "Other containers on this network reach it by IP or by its name, " + name
+		}
+		if network == "bridge" {
+			meaning = "The default bridge network: containers on it can only reach each other by IP, not by name"
+		}
+		inspectField(network, detail, meaning+".")
+	}
+
+	inspectSection("Mounts")
+	printInspectMounts(o.get("Mounts"))
+
+	inspectSection("Limits and privileges (HostConfig)")
+	printInspectLimits(o)
+}
+
+func printInspectEnv(env []string) {
+	if len(env) == 0 {
+		return
+	}
+	inspectField("Env", plural(len(env), "variable"))
+	for _, e := range env {
+		name, value, _ := strings.Cut(e, "=")
+		if secretVariable.MatchString(name) && value != "" {
+			inspectField("", name+"=****", "!!! Looks like a secret. Anyone who can run docker inspect can read it; prefer secrets or mounted files.")
+			continue
+		}
+		inspectField("", e)
+	}
+}
+
+// secretVariable matches whole parts of a variable name, so that
+// DB_PASSWORD is a secret but PASSENGER_APP_ENV and TOKENIZERS_PARALLELISM
+// are not.
+var secretVariable = regexp.MustCompile(`(?i)(^|_)(PASS(WORD|WD)?|SECRET|TOKEN|API_?KEY|ACCESS_KEY|PRIVATE_KEY|CREDENTIALS?)(_|$)`)
+
+func printInspectPorts(ports map[string]any) {
+	if len(ports) == 0 {
+		fmt.Println("  No ports are exposed or published.")
+		return
+	}
+	for _, port := range sortedKeys(ports) {
+		bindings, _ := ports[port].([]any)
+		if len(bindings) == 0 {
+			inspectField(port, "(not published)", "Exposed by the image but only reachable from other containers. Publish it with -p.")
+			continue
+		}
+		for _, b := range bindings {
+			fields, _ := b.(map[string]any)
+			binding := inspectObject(fields)
+			ip, hostPort := binding.str("HostIp"), binding.str("HostPort")
+			var meaning string
+			switch ip {
+			case "", "0.0.0.0", "::":
+				meaning = fmt.Sprintf("Published on port %s of every host interface: reachable from other machines unless a firewall blocks it.", hostPort)
+			case "127.0.0.1", "::1":
+				meaning = fmt.Sprintf("Published on port %s of localhost only: reachable from this machine.", hostPort)
+			default:
+				meaning = fmt.Sprintf("Published on port %s of the host address %s.", hostPort, ip)
+			}
+			inspectField(port, fmt.Sprintf("-> %s:%s", firstNonEmpty(ip, "0.0.0.0"), hostPort), meaning)
+		}
+	}
+}
+
+func printInspectMounts(v any) {
+	mounts, _ := v.([]any)
+	if len(mounts) == 0 {
+		fmt.Println("  No volumes or bind mounts: everything written is lost when the container is removed.")
+		return
+	}
+	for _, item := range mounts {
+		fields, _ := item.(map[string]any)
+		m := inspectObject(fields)
+		mode := "read-write"
+		if !m.flag("RW") {
+			mode = "read-only"
+		}
+		var meaning string
+		switch m.str("Type") {
+		case "volume":
+			meaning = fmt.Sprintf("The named volume %s, managed by Docker and kept when the container is removed. Stored at %s.", firstNonEmpty(m.str("Name"), "(anonymous)"), m.str("Source"))
+		case "bind":
+			meaning = fmt.Sprintf("The host path %s, shared with the container: changes are visible on both sides.", m.str("Source"))
+		case "tmpfs":
+			meaning = "Kept in memory and lost when the container stops."
+		default:
+			meaning = "A " + m.str("Type") + " mount from " + m.str("Source") + "."
+		}
+		inspectField(m.str("Destination"), fmt.Sprintf("%s, %s", m.str("Type"), mode), meaning)
+	}
+}
+
+func printInspectLimits(o inspectObject) {
+	if memory := o.num("HostConfig.Memory"); memory > 0 {
+		inspectField("Memory", describeBytes(memory), "Above this the kernel's OOM killer stops a process (exit code 137).")
+	} else {
+		inspectField("Memory", "0", "No memory limit: it can use all of the host's memory.")
+	}
+	switch swap := o.num("HostConfig.MemorySwap"); {
+	case swap == -1:
+		inspectField("MemorySwap", "-1", "Unlimited swap.")
+	case swap > 0:
+		inspectField("MemorySwap", describeBytes(swap), "Memory plus swap; the difference to Memory is swap.")
+	}
+	if cpus := o.num("HostConfig.NanoCpus"); cpus > 0 {
+		inspectField("NanoCpus", fmt.Sprint(cpus), fmt.Sprintf("At most %g CPUs' worth of time (--cpus).", float64(cpus)/1e9))
+	} else if quota := o.num("HostConfig.CpuQuota"); quota > 0 {
+		period := o.num("HostConfig.CpuPeriod")
+		if period == 0 {
+			period = 100000
+		}
+		inspectField("CpuQuota", fmt.Sprint(quota), fmt.Sprintf("At most %g CPUs' worth of time.", float64(quota)/float64(period)))
+	} else {
+		inspectField("NanoCpus", "0", "No CPU limit.")
+	}
+	if set := o.str("HostConfig.CpusetCpus"); set != "" {
+		inspectField("CpusetCpus", set, "Only runs on these CPUs.")
+	}
+	if pids := o.num("HostConfig.PidsLimit"); pids > 0 {
+		inspectField("PidsLimit", fmt.Sprint(pids), "At most this many processes and threads.")
+	}
+
+	policy := o.str("HostConfig.RestartPolicy.Name")
+	meaning := map[string]string{
+		"":               "Never restarted automatically.",
+		"no":             "Never restarted automatically.",
+		"always":         "Restarted whenever it stops, and when the Docker daemon starts.",
+		"unless-stopped": "Restarted whenever it stops, unless you stopped it with docker stop.",
+		"on-failure":     fmt.Sprintf("Restarted when it exits with a non-zero code, up to %d times (0 means no limit).", o.num("HostConfig.RestartPolicy.MaximumRetryCount")),
+	}[policy]
+	inspectField("RestartPolicy", firstNonEmpty(policy, "no"), meaning)
+
+	if o.flag("HostConfig.Privileged") {
+		inspectField("Privileged", "true", "!!! Has every capability and access to all host devices: a process in it can take over the host.")
+	}
+	if caps := o.list("HostConfig.CapAdd"); len(caps) > 0 {
+		inspectField("CapAdd", strings.Join(caps, ", "), "Extra Linux capabilities beyond Docker's default set.")
+	}
+	if o.flag("HostConfig.ReadonlyRootfs") {
+		inspectField("ReadonlyRootfs", "true", "The image's file system is read-only; only mounts are writable.")
+	}
+	if logType := o.str("HostConfig.LogConfig.Type"); logType != "" {
+		inspectField("LogConfig", logType, "Where docker logs output goes.")
+	}
+}
+
+func printInspectImage(o inspectObject) {
+	tags := o.list("RepoTags")
+	fmt.Printf("Image %s (%s)\n", firstNonEmpty(strings.Join(tags, ", "), "<untagged>"), shortID(o.str("Id")))
+
+	inspectSection("Identity")
+	inspectField("Id", shortID(o.str("Id")), "The hash of the image configuration: the same ID means the same image.")
+	if len(tags) == 0 {
+		inspectField("RepoTags", "(none)", "A dangling image: no tag points to it anymore. docker image prune removes such images.")
+	}
+	for _, d := range o.list("RepoDigests") {
+		inspectField("RepoDigest", d, "The registry's manifest digest. Pull it by this name to always get this exact image.")
+	}
+	if created := inspectTime(o.str("Created")); !created.IsZero() {
+		inspectField("Created", o.str("Created"), "Built "+timeAgo(created)+".")
+	}
+	inspectField("Platform", o.str("Os")+"/"+o.str("Architecture"), "Runs natively only on hosts of this platform.")
+	inspectField("Size", formatSize(o.num("Size")), "Uncompressed, including all layers.")
+	if layers := o.list("RootFS.Layers"); len(layers) > 0 {
+		inspectField("RootFS.Layers", plural(len(layers), "layer"), "Each RUN, COPY or ADD in the Dockerfile adds one; layers are shared between images.")
+	}
+
+	inspectSection("Defaults for containers (Config)")
+	entrypoint, command := o.list("Config.Entrypoint"), o.list("Config.Cmd")
+	inspectField("Entrypoint", inspectList(entrypoint), "The program a container starts.")
+	inspectField("Cmd", inspectList(command), "Default arguments, replaced by anything after the image name in docker run.")
+	inspectField("User", firstNonEmpty(o.str("Config.User"), "(empty: root)"))
+	if dir := o.str("Config.WorkingDir"); dir != "" {
+		inspectField("WorkingDir", dir)
+	}
+	if ports := o.object("Config.ExposedPorts"); len(ports) > 0 {
+		inspectField("ExposedPorts", strings.Join(sortedKeys(ports), ", "), "Documentation only: they are not published until docker run -p or -P.")
+	}
+	printInspectEnv(o.list("Config.Env"))
+	if volumes := o.object("Config.Volumes"); len(volumes) > 0 {
+		inspectField("Volumes", strings.Join(sortedKeys(volumes), ", "), "Each container gets an anonymous volume here unless you mount something.")
+	}
+}
+
+func printInspectNetwork(o inspectObject) {
+	fmt.Printf("Network %s (%s)\n", o.str("Name"), shortID(o.str("Id")))
+	fmt.Print("\n")
+	driver := o.str("Driver")
+	meaning := map[string]string{
+		"bridge":  "A private network on this host; containers reach the outside through NAT.",
+		"host":    "Containers use the host's network stack directly.",
+		"overlay": "Spans several Docker hosts in a swarm.",
+		"macvlan": "Containers get their own MAC address on the physical network.",
+		"ipvlan":  "Containers get addresses on the physical network, sharing the host's MAC address.",
+		"null":    "No networking.",
+	}[driver]
+	inspectField("Driver", driver, meaning)
+	inspectField("Scope", o.str("Scope"), "local: this host only; swarm: the whole cluster.")
+	if o.flag("Internal") {
+		inspectField("Internal", "true", "No route to the outside world; only containers on the network can talk.")
+	}
+	if configs, _ := o.get("IPAM.Config").([]any); len(configs) > 0 {
+		for _, c := range configs {
+			fields, _ := c.(map[string]any)
+			cfg := inspectObject(fields)
+			inspectField("Subnet", cfg.str("Subnet"), "Addresses are handed out from this range; the gateway is "+firstNonEmpty(cfg.str("Gateway"), "chosen by Docker")+".")
+		}
+	}
+	containers := o.object("Containers")
+	inspectField("Containers", fmt.Sprint(len(containers)), "Containers connected right now.")
+	for _, id := range sortedKeys(containers) {
+		fields, _ := containers[id].(map[string]any)
+		c := inspectObject(fields)
+		inspectField("", fmt.Sprintf("%s  %s", c.str("Name"), c.str("IPv4Address")))
+	}
+	if driver == "bridge" && o.str("Name") != "bridge" {
+		fmt.Print("\n")
+		fmt.Println("Containers on a user-defined bridge network can reach each other by container name.")
+	}
+}
+
+func printInspectVolume(o inspectObject) {
+	fmt.Printf("Volume %s\n", o.str("Name"))
+	fmt.Print("\n")
+	driver := o.str("Driver")
+	meaning := "Stored on another system by a volume plugin."
+	if driver == "local" {
+		meaning = "Stored on this host."
+	}
+	inspectField("Driver", driver, meaning)
+	inspectField("Mountpoint", o.str("Mountpoint"), "Where the data lives on the host (inside the Docker VM on Docker Desktop).")
+	if created := inspectTime(o.str("CreatedAt")); !created.IsZero() {
+		inspectField("CreatedAt", o.str("CreatedAt"), "Created "+timeAgo(created)+".")
+	}
+	labels := o.object("Labels")
+	if project, ok := labels["com.docker.compose.project"].(string); ok {
+		inspectField("Labels", "compose project "+project, "Created by Docker Compose; docker compose down -v removes it.")
+	}
+	if len(o.list("Options")) > 0 || len(o.object("Options")) > 0 {
+		inspectField("Options", fmt.Sprint(o.get("Options")), "Driver options, e.g. an NFS server or a tmpfs size.")
+	}
+	fmt.Print("\n")
+	fmt.Println("The volume outlives containers. Remove it with:")
+	fmt.Printf("$ docker volume rm %s\n", o.str("Name"))
+}
+
+// describeExitCode explains a container's exit code, including the
+// 128+signal codes of processes killed by a signal.
+func describeExitCode(code int64) string {
+	switch code {
+	case 0:
+		return "The process finished successfully."
+	case 1:
+		return "The application reported an error; docker logs shows why."
+	case 125:
+		return "docker run itself failed, e.g. because of an invalid flag."
+	case 126:
+		return "The command could not be executed: it is not executable or is a directory."
+	case 127:
+		return "The command was not found in the image."
+	case 130:
+		return "Interrupted by SIGINT (128 + 2), e.g. Ctrl-C."
+	case 137:
+		return "Killed by SIGKILL (128 + 9): docker kill, the OOM killer, or docker stop after the grace period."
+	case 139:
+		return "Crashed with a segmentation fault, SIGSEGV (128 + 11)."
+	case 143:
+		return "Stopped by SIGTERM (128 + 15), as docker stop sends."
+	}
+	if code > 128 && code < 160 {
+		return fmt.Sprintf("Killed by signal %d (exit code 128 + %d).", code-128, code-128)
+	}
+	return "The application exited with an error; docker logs shows why."
+}
+
+func inspectTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil || t.Year() < 2 {
+		return time.Time{}
+	}
+	return t
+}
+
+func inspectList(list []string) string {
+	if len(list) == 0 {
+		return "(none)"
+	}
+	data, _ := json.Marshal(list)
+	return string(data)
+}
+
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,417 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var lintFormat string
+
+var dockerLintCmd = &cobra.Command{
+	Use:   "lint [Dockerfile]",
+	Short: "Checks a Dockerfile for common mistakes and explains how to fix them",
+	Long: `This command reads a Dockerfile and flags common problems: running as root,
+ADD with URLs, base images without a pinned tag, apt-get update in a layer of
+its own, secrets in ENV or ARG, shell-form CMD and a missing HEALTHCHECK. Each
+issue comes with an explanation and a suggested rewrite. --format json and
+--format github print the issues for CI. In every format the command exits with
+status 1 when it finds an error or a warning, and with status 2 when it cannot
+read the Dockerfile.
+For example:
+
+- explain docker lint
+- explain docker lint build/Dockerfile.prod
+- explain docker lint --format github Dockerfile`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		file := "Dockerfile"
+		if len(args) == 1 {
+			file = args[0]
+		}
+		if lintFormat != "text" && lintFormat != "json" && lintFormat != "github" {
+			fmt.Printf("Unknown format '%s'. Use text, json or github.\n", lintFormat)
+			os.Exit(2)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			printLintError(file, err)
+			os.Exit(2)
+		}
+		issues := lintDockerfile(parseDockerfile(string(data)))
+
+		switch lintFormat {
+		case "json":
+			printLintJSON(file, issues)
+		case "github":
+			printLintGitHub(file, issues)
+		default:
+			printLintText(file, issues)
+		}
+		for _, issue := range issues {
+			if issue.Severity != "info" {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func init() {
+	dockerCmd.AddCommand(dockerLintCmd)
+	dockerLintCmd.Flags().StringVarP(&lintFormat, "format", "f", "text", "Output format: text, json, or github for GitHub Actions annotations")
+}
+
+// dockerInstruction is one instruction of a Dockerfile, with continuation
+// lines joined and heredoc bodies attached.
+type dockerInstruction struct {
+	line    int // where the instruction starts
+	keyword string
+	args    string
+}
+
+var (
+	heredocStart    = regexp.MustCompile(`<<-?["']?(\w+)["']?`)
+	escapeDirective = regexp.MustCompile("^#\\s*escape\\s*=\\s*([\\\\`])\\s*$")
+)
+
+func parseDockerfile(text string) []dockerInstruction {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	escape := `\`
+	var instructions []dockerInstruction
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if m := escapeDirective.FindStringSubmatch(line); m != nil && len(instructions) == 0 {
+			escape = m[1]
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		start := i + 1
+		var full strings.Builder
+		for {
+			trimmed := strings.TrimSpace(lines[i])
+			if !strings.HasPrefix(trimmed, "#") {
+				if body, ok := strings.CutSuffix(trimmed, escape); ok {
+					full.WriteString(body + " ")
+				} else {
+					full.WriteString(trimmed)
+					break
+				}
+			}
+			if i+1 >= len(lines) {
+				break
+			}
+			i++
+		}
+
+		keyword, args, _ := strings.Cut(full.String(), " ")
+		inst := dockerInstruction{line: start, keyword: strings.ToUpper(keyword), args: strings.TrimSpace(args)}
+		if m := heredocStart.FindStringSubmatch(inst.args); m != nil {
+			for i+1 < len(lines) {
+				i++
+				if strings.TrimSpace(lines[i]) == m[1] {
+					break
+				}
+				inst.args += "\n" + lines[i]
+			}
+		}
+		instructions = append(instructions, inst)
+	}
+	return instructions
+}
+
+// lintIssue is one finding. The exported fields are the JSON output.
+type lintIssue struct {
+	Line        int    `json:"line"`
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	Explanation string `json:"explanation"`
+	Suggestion  string `json:"suggestion,omitempty"`
+}
+
+var (
+	fromPattern      = regexp.MustCompile(`(?i)^(?:--platform=\S+\s+)?(\S+)(?:\s+AS\s+(\S+))?$`)
+	urlPattern       = regexp.MustCompile(`^https?://`)
+	archiveExtension = regexp.MustCompile(`\.(tar|tar\.gz|tgz|tar\.bz2|tar\.xz|txz)$`)
+	aptUpdate        = regexp.MustCompile(`\bapt(-get)? update\b`)
+	aptInstall       = regexp.MustCompile(`\bapt(-get)?(?:\s+-\S+)*\s+install\b((?:\s+[^&|;]+)*)`)
+	aptCleanup       = regexp.MustCompile(`rm -rf /var/lib/apt/lists`)
+	cdCommand        = regexp.MustCompile(`(?:^|&&|;)\s*cd\s+(\S+)\s*(?:&&|;)`)
+	envAssignment    = regexp.MustCompile(`(\w+)=("[^"]*"|'[^']*'|\S*)`)
+)
+
+func lintDockerfile(instructions []dockerInstruction) []lintIssue {
+	var issues []lintIssue
+	add := func(inst dockerInstruction, rule, severity, message, explanation, suggestion string) {
+		issues = append(issues, lintIssue{Line: inst.line, Rule: rule, Severity: severity, Message: message, Explanation: explanation, Suggestion: suggestion})
+	}
+
+	stages := map[string]bool{}
+	var lastFrom dockerInstruction
+	var user, healthcheck *dockerInstruction
+	var cmds []dockerInstruction
+	for i, inst := range instructions {
+		switch inst.keyword {
+		case "FROM":
+			lastFrom = inst
+			user, healthcheck, cmds = nil, nil, nil
+			m := fromPattern.FindStringSubmatch(inst.args)
+			if m == nil {
+				continue
+			}
+			if m[2] != "" {
+				stages[strings.ToLower(m[2])] = true
+			}
+			image := m[1]
+			if stages[strings.ToLower(image)] || image == "scratch" || strings.Contains(image, "$") || strings.Contains(image, "@") {
+				continue
+			}
+			ref, err := parseImageReference(image)
+			if err != nil {
+				continue
+			}
+			if ref.defaultTag || ref.tag == "latest" {
+				base := strings.TrimSuffix(image, ":latest")
+				add(inst, "unpinned-base", "warning",
+					fmt.Sprintf("The base image %s is not pinned to a version", image),
+					"Without a tag, or with latest, every build may start from a different image, so builds are not reproducible and can break without any change on your side.",
+					"FROM "+strings.Replace(inst.args, image, base+":<version>", 1)+"   (or pin the digest: "+base+"@sha256:<digest>)")
+			}
+
+		case "USER":
+			u := inst
+			user = &u
+
+		case "HEALTHCHECK":
+			h := inst
+			healthcheck = &h
+
+		case "CMD", "ENTRYPOINT":
+			if inst.keyword == "CMD" {
+				cmds = append(cmds, inst)
+			}
+			if strings.HasPrefix(inst.args, "[") {
+				continue
+			}
+			add(inst, "shell-form-"+strings.ToLower(inst.keyword), "warning",
+				fmt.Sprintf("%s uses the shell form", inst.keyword),
+				"The shell form runs the command under /bin/sh -c, so the shell is PID 1: docker stop's SIGTERM never reaches your program, which is killed after the grace period.",
+				inst.keyword+" "+execForm(inst.args))
+
+		case "ADD":
+			args := strings.Fields(inst.args)
+			var sources []string
+			for _, a := range args {
+				if !strings.HasPrefix(a, "--") {
+					sources = append(sources, a)
+				}
+			}
+			if len(sources) < 2 {
+				continue
+			}
+			dest := sources[len(sources)-1]
+			for _, src := range sources[:len(sources)-1] {
+				switch {
+				case urlPattern.MatchString(src):
+					target := dest
+					if strings.HasSuffix(dest, "/") {
+						target = dest + path.Base(src)
+					}
+					add(inst, "add-url", "warning",
+						fmt.Sprintf("ADD downloads %s", src),
+						"ADD fetches URLs without verifying them and always re-downloads, so builds depend on a remote server and the cache is less useful. Download explicitly, or give ADD a --checksum.",
+						fmt.Sprintf("RUN curl -fsSL %s -o %s", src, target))
+				case !archiveExtension.MatchString(src):
+					add(inst, "add-instead-of-copy", "info",
+						fmt.Sprintf("ADD copies %s, which is not a URL or archive", src),
+						"ADD also extracts archives and downloads URLs. COPY does only what it says, which makes the Dockerfile easier to read.",
+						"COPY "+inst.args)
+				}
+			}
+
+		case "ENV", "ARG":
+			for _, m := range envAssignment.FindAllStringSubmatch(inst.args, -1) {
+				if !secretVariable.MatchString(m[1]) || m[2] == "" || m[2] == `""` {
+					continue
+				}
+				add(inst, "secret-in-"+strings.ToLower(inst.keyword), "error",
+					fmt.Sprintf("%s sets %s, which looks like a secret", inst.keyword, m[1]),
+					fmt.Sprintf("Values set with %s are stored in the image: anyone who can pull it can read them with docker history or docker inspect.", inst.keyword),
+					fmt.Sprintf("RUN --mount=type=secret,id=%s %s=$(cat /run/secrets/%s) <command>   (build with --secret id=%s,env=%s)", strings.ToLower(m[1]), m[1], strings.ToLower(m[1]), strings.ToLower(m[1]), m[1]))
+			}
+
+		case "MAINTAINER":
+			add(inst, "maintainer", "info", "MAINTAINER is deprecated",
+				"Docker replaced MAINTAINER with labels, which tools can read.",
+				fmt.Sprintf("LABEL org.opencontainers.image.authors=%q", inst.args))
+
+		case "RUN":
+			issues = append(issues, lintRun(inst, instructions[i+1:])...)
+		}
+	}
+
+	if len(instructions) == 0 {
+		return issues
+	}
+	if user == nil || user.args == "root" || user.args == "0" || strings.HasPrefix(user.args, "root:") || strings.HasPrefix(user.args, "0:") {
+		at := lastFrom
+		if user != nil {
+			at = *user
+		}
+		add(at, "runs-as-root", "warning", "The final image runs as root",
+			"A process that breaks out of the application has root rights in the container, and on a misconfigured host possibly beyond it.",
+			"RUN groupadd --system app && useradd --system --gid app app\nUSER app   (on Alpine: RUN addgroup -S app && adduser -S -G app app)")
+	}
+	if healthcheck == nil {
+		add(lastFrom, "no-healthcheck", "info", "The final image has no HEALTHCHECK",
+			"Without one Docker only knows whether the process runs, not whether it works. Orchestrators such as Compose and Swarm use the health status for restarts and dependencies.",
+			"HEALTHCHECK --interval=30s --timeout=3s CMD curl -f http://localhost:<port>/ || exit 1")
+	}
+	if len(cmds) > 1 {
+		add(cmds[0], "multiple-cmd", "warning", fmt.Sprintf("The final stage has %d CMD instructions", len(cmds)),
+			"Only the last CMD takes effect; the others are ignored.", "")
+	}
+	return issues
+}
+
+func lintRun(inst dockerInstruction, following []dockerInstruction) []lintIssue {
+	var issues []lintIssue
+	add := func(rule, severity, message, explanation, suggestion string) {
+		issues = append(issues, lintIssue{Line: inst.line, Rule: rule, Severity: severity, Message: message, Explanation: explanation, Suggestion: suggestion})
+	}
+	cmd := inst.args
+
+	install := aptInstall.FindStringSubmatch(cmd)
+	if aptUpdate.MatchString(cmd) && install == nil {
+		packages := "<packages>"
+		if len(following) > 0 && following[0].keyword == "RUN" {
+			if m := aptInstall.FindStringSubmatch(following[0].args); m != nil {
+				// The suggestion brings its own flags; keep only the package names.
+				var names []string
+				for _, word := range strings.Fields(m[2]) {
+					if !strings.HasPrefix(word, "-") {
+						names = append(names, word)
+					}
+				}
+				if len(names) > 0 {
+					packages = strings.Join(names, " ")
+				}
+			}
+		}
+		add("apt-update-alone", "warning", "apt-get update runs in a layer of its own",
+			"The build cache keeps that layer, so a later apt-get install in another RUN can use stale package lists and fail or install old versions. Update and install in the same RUN.",
+			fmt.Sprintf("RUN apt-get update && apt-get install -y --no-install-recommends %s && rm -rf /var/lib/apt/lists/*", packages))
+	}
+	if install != nil && !aptCleanup.MatchString(cmd) {
+		add("apt-lists-kept", "info", "apt-get install leaves the package lists in the image",
+			"The lists in /var/lib/apt/lists take tens of megabytes. Removing them in a later RUN does not help, because the earlier layer keeps them.",
+			"RUN "+strings.TrimSpace(cmd)+" && rm -rf /var/lib/apt/lists/*")
+	}
+	if strings.Contains(cmd, "sudo ") {
+		add("sudo", "warning", "RUN uses sudo",
+			"Build steps already run as root unless a USER says otherwise, and sudo in an image is an easy privilege escalation. Switch users with USER instead.",
+			"RUN "+strings.ReplaceAll(cmd, "sudo ", ""))
+	}
+	if m := cdCommand.FindStringSubmatch(cmd); m != nil {
+		add("cd-in-run", "info", fmt.Sprintf("RUN changes directory with cd %s", m[1]),
+			"cd only lasts for this RUN. WORKDIR sets the directory for every following instruction and documents it.",
+			fmt.Sprintf("WORKDIR %s", m[1]))
+	}
+	return issues
+}
+
+// execForm rewrites a shell-form command as a JSON array. Commands that
+// need a shell keep one, with exec so the program replaces it as PID 1.
+func execForm(command string) string {
+	if strings.ContainsAny(command, "|&;<>$*?`") {
+		data, _ := json.Marshal([]string{"/bin/sh", "-c", "exec " + command})
+		return string(data)
+	}
+	data, _ := json.Marshal(splitCommandLine(command))
+	return string(data)
+}
+
+func lintCounts(issues []lintIssue) string {
+	counts := map[string]int{}
+	for _, issue := range issues {
+		counts[issue.Severity]++
+	}
+	return fmt.Sprintf("%s, %s, %s", plural(counts["error"], "error"), plural(counts["warning"], "warning"), fmt.Sprintf("%d info", counts["info"]))
+}
+
+func printLintText(file string, issues []lintIssue) {
+	if len(issues) == 0 {
+		fmt.Printf("%s: no issues found.\n", file)
+		return
+	}
+	for i, issue := range issues {
+		if i > 0 {
+			fmt.Print("\n")
+		}
+		fmt.Printf("%s:%d  %s  %s (%s)\n", file, issue.Line, issue.Severity, issue.Message, issue.Rule)
+		fmt.Printf("  Why: %s\n", issue.Explanation)
+		if issue.Suggestion != "" {
+			fmt.Println("  Instead:")
+			for _, line := range strings.Split(issue.Suggestion, "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+	fmt.Print("\n")
+	fmt.Printf("Found %s.\n", lintCounts(issues))
+	fmt.Printf("See: %s\n", dockerCommandTopic("build"))
+}
+
+func printLintJSON(file string, issues []lintIssue) {
+	if issues == nil {
+		issues = []lintIssue{}
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(struct {
+		File   string      `json:"file"`
+		Issues []lintIssue `json:"issues"`
+	}{file, issues})
+}
+
+// printLintError reports a Dockerfile that could not be read in the
+// requested format, so CI tools that parse the output still can.
+func printLintError(file string, err error) {
+	message := "Could not read the Dockerfile: " + err.Error()
+	switch lintFormat {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(struct {
+			File  string `json:"file"`
+			Error string `json:"error"`
+		}{file, message})
+	case "github":
+		fmt.Printf("::error file=%s::%s\n", file, message)
+	default:
+		fmt.Println(message)
+	}
+}
+
+// printLintGitHub prints workflow commands that GitHub Actions shows as
+// annotations on the pull request.
+func printLintGitHub(file string, issues []lintIssue) {
+	escape := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	for _, issue := range issues {
+		level := map[string]string{"error": "error", "warning": "warning", "info": "notice"}[issue.Severity]
+		message := issue.Message + ". " + issue.Explanation
+		if issue.Suggestion != "" {
+			message += "\nInstead: " + issue.Suggestion
+		}
+		fmt.Printf("::%s file=%s,line=%d,title=%s::%s\n", level, file, issue.Line, issue.Rule, escape.Replace(message))
+	}
+}
@@ -0,0 +1,534 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var errorCmd = &cobra.Command{
+	Use:   "error [message]",
+	Short: "Explains a git, docker or kubectl error message",
+	Long: `This command explains the cause of a git, docker or kubectl error message and
+suggests how to fix it. The message can be passed as an argument or piped through stdin.
+For example:
+
+- explain error 'fatal: refusing to merge unrelated histories'
+- git push 2>&1 | explain error`,
+	Run: func(cmd *cobra.Command, args []string) {
+		text := strings.Join(args, " ")
+		if text == "" || text == "-" {
+			if !stdinIsPiped() {
+				fmt.Println(`Please provide an error message, for example:
+explain error 'fatal: refusing to merge unrelated histories'`)
+				return
+			}
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				fmt.Println("Could not read the error message:", err)
+				return
+			}
+			text = string(data)
+		}
+
+		matches := matchErrors(text)
+		if len(matches) == 0 {
+			fmt.Println("Explanation for this error is not available.")
+			if related := newSearchIndex(searchDocuments()).search(text); len(related) > 0 {
+				fmt.Print("\n")
+				fmt.Println("These topics may be related:")
+				for i, m := range related {
+					if i == 3 {
+						break
+					}
+					fmt.Printf("  %-36s %s\n", m.doc.command, m.doc.title)
+				}
+			}
+			return
+		}
+		for i, m := range matches {
+			if i > 0 {
+				fmt.Print("\n")
+			}
+			printErrorMatch(m)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(errorCmd)
+}
+
+// errorPattern recognises an error message. The cause and fix may refer to
+// named groups of the pattern, e.g. ${path}, to quote the offending value.
+type errorPattern struct {
+	tool    string
+	title   string
+	pattern *regexp.Regexp
+	cause   string
+	fix     []string
+	topics  []topicRef
+}
+
+// errorMatch is an error pattern with its placeholders filled in from the
+// message that matched it.
+type errorMatch struct {
+	pattern errorPattern
+	cause   string
+	fix     []string
+}
+
+var gitErrorPatterns = []errorPattern{
+	{
+		tool:    "git",
+		title:   "Push rejected: the remote branch has commits you do not have",
+		pattern: regexp.MustCompile(`(?i)Updates were rejected because (?:the tip of your current branch is behind|the remote contains work that you do\s+not have)|\[rejected\]\s+\S+ -> \S+ \((?:non-fast-forward|fetch first)\)`),
+		cause:   "Someone pushed to the remote branch since you last pulled. Git refuses to overwrite their commits with yours.",
+		fix:     []string{"git pull --rebase", "git push"},
+		topics:  []topicRef{gitCommandTopic("pull"), gitAdvancedTopic("rebase"), gitCommandTopic("merge"), gitCommandTopic("push")},
+	},
+	{
+		tool:    "git",
+		title:   "Push rejected by a server-side hook",
+		pattern: regexp.MustCompile(`(?i)\[remote rejected\].*\((?:pre-receive hook declined|protected branch hook declined)\)|GH006: Protected branch update failed`),
+		cause:   "The server refused the push, usually because the branch is protected and only accepts changes through pull requests.",
+		fix:     []string{"git switch -c my-change", "git push -u origin my-change", "# then open a pull request"},
+		topics:  []topicRef{gitCommandTopic("push"), gitCommandTopic("branch")},
+	},
+	{
+		tool:    "git",
+		title:   "Refusing to merge unrelated histories",
+		pattern: regexp.MustCompile(`(?i)refusing to merge unrelated histories`),
+		cause:   "The two branches share no common commit, typically because the remote repository was created with its own initial commit (a README or licence) while you initialized locally.",
+		fix:     []string{"git pull origin main --allow-unrelated-histories", "# resolve any conflicts, then", "git push"},
+		topics:  []topicRef{gitCommandTopic("merge"), gitCommandTopic("pull")},
+	},
+	{
+		tool:    "git",
+		title:   "Pull needs to know how to reconcile divergent branches",
+		pattern: regexp.MustCompile(`(?i)Need to specify how to reconcile divergent branches|You have divergent branches`),
+		cause:   "Both your branch and the remote branch have new commits. Git wants you to choose between merging and rebasing.",
+		fix:     []string{"git pull --rebase            # replay your commits on top", "git pull --no-rebase         # create a merge commit", "git config pull.rebase true  # make rebasing the default"},
+		topics:  []topicRef{gitCommandTopic("pull"), gitAdvancedTopic("rebase"), gitCommandTopic("merge")},
+	},
+	{
+		tool:    "git",
+		title:   "Branch has no upstream",
+		pattern: regexp.MustCompile(`(?i)The current branch (?P<branch>\S+) has no upstream branch`),
+		cause:   "Branch '${branch}' has never been pushed, so git does not know which remote branch it belongs to.",
+		fix:     []string{"git push -u origin ${branch}"},
+		topics:  []topicRef{gitCommandTopic("push"), gitCommandTopic("branch")},
+	},
+	{
+		tool:    "git",
+		title:   "No tracking information for the current branch",
+		pattern: regexp.MustCompile(`(?i)There is no tracking information for the current branch`),
+		cause:   "The branch is not linked to a remote branch, so 'git pull' does not know what to pull.",
+		fix:     []string{"git branch --set-upstream-to=origin/<branch>", "git pull"},
+		topics:  []topicRef{gitCommandTopic("pull"), gitCommandTopic("branch")},
+	},
+	{
+		tool:    "git",
+		title:   "Nothing to push: the refspec does not exist",
+		pattern: regexp.MustCompile(`(?i)src refspec (?P<ref>\S+) does not match any`),
+		cause:   "There is no local branch or commit named '${ref}'. Either nothing has been committed yet, or the branch is called something else (for example 'master' instead of 'main').",
+		fix:     []string{"git branch              # list your branches", "git commit -m 'Initial commit'   # if there are no commits yet"},
+		topics:  []topicRef{gitCommandTopic("commit"), gitCommandTopic("branch"), gitCommandTopic("push")},
+	},
+	{
+		tool:    "git",
+		title:   "Local changes would be overwritten",
+		pattern: regexp.MustCompile(`(?i)Your local changes to the following files would be overwritten by (?P<operation>\w+)`),
+		cause:   "You have uncommitted changes in files that the ${operation} needs to modify. Git stops rather than lose them.",
+		fix:     []string{"git stash", "# run the command again, then", "git stash pop"},
+		topics:  []topicRef{gitAdvancedTopic("stash"), gitCommandTopic("commit")},
+	},
+	{
+		tool:    "git",
+		title:   "Merge conflict",
+		pattern: regexp.MustCompile(`CONFLICT \([^)]+\): (?:Merge conflict in )?(?P<path>\S+)`),
+		cause:   "Both sides changed the same part of ${path}. Git marked the conflicting sections with <<<<<<< and >>>>>>> markers.",
+		fix:     []string{"git status                # list conflicted files", "# edit the files and remove the markers, then", "git add ${path}", "git commit                # or git rebase --continue"},
+		topics:  []topicRef{gitCommandTopic("merge"), gitCommandTopic("status"), gitAdvancedTopic("rebase")},
+	},
+	{
+		tool:    "git",
+		title:   "Merge still in progress",
+		pattern: regexp.MustCompile(`(?i)You have not concluded your merge \(MERGE_HEAD exists\)|Merging is not possible because you have unmerged files`),
+		cause:   "An earlier merge stopped with conflicts and was never committed or aborted.",
+		fix:     []string{"git commit        # if the conflicts are resolved", "git merge --abort # to give up the merge"},
+		topics:  []topicRef{gitCommandTopic("merge"), gitCommandTopic("status")},
+	},
+	{
+		tool:    "git",
+		title:   "Rebase already in progress",
+		pattern: regexp.MustCompile(`(?i)It seems that there is already a rebase-(?:merge|apply) directory`),
+		cause:   "A previous rebase was interrupted and is still in progress.",
+		fix:     []string{"git rebase --continue   # after resolving conflicts", "git rebase --abort      # to go back to where you started"},
+		topics:  []topicRef{gitAdvancedTopic("rebase")},
+	},
+	{
+		tool:    "git",
+		title:   "Detached HEAD",
+		pattern: regexp.MustCompile(`(?i)You are in 'detached HEAD' state|HEAD detached at \S+`),
+		cause:   "HEAD points directly at a commit instead of a branch. New commits will not belong to any branch and are easy to lose.",
+		fix:     []string{"git switch -c new-branch   # keep working on a new branch", "git switch -                # go back to the previous branch"},
+		topics:  []topicRef{gitCommandTopic("branch"), gitAdvancedTopic("reflog")},
+	},
+	{
+		tool:    "git",
+		title:   "Not a Git repository",
+		pattern: regexp.MustCompile(`(?i)not a git repository`),
+		cause:   "The current directory, and none of its parents, contains a .git directory.",
+		fix:     []string{"cd path/to/your/repository", "# or create one here", "git init"},
+		topics:  []topicRef{gitCommandTopic("init"), gitCommandTopic("clone")},
+	},
+	{
+		tool:    "git",
+		title:   "Path not known to Git",
+		pattern: regexp.MustCompile(`(?i)pathspec '(?P<path>[^']+)' did not match any file\(s\) known to git`),
+		cause:   "'${path}' is misspelled, does not exist, or is not tracked yet.",
+		fix:     []string{"git status   # check the exact file name", "git add ${path}"},
+		topics:  []topicRef{gitCommandTopic("add"), gitCommandTopic("status")},
+	},
+	{
+		tool:    "git",
+		title:   "Unknown revision",
+		pattern: regexp.MustCompile(`(?i)ambiguous argument '[^']+': unknown revision|bad revision '[^']+'|not a valid object name`),
+		cause:   "The commit, branch or tag you named does not exist locally. It may be misspelled or not fetched yet.",
+		fix:     []string{"git fetch", "git log --oneline --all   # find the right name"},
+		topics:  []topicRef{gitCommandTopic("log"), gitCommandTopic("fetch")},
+	},
+	{
+		tool:    "git",
+		title:   "Branch already exists",
+		pattern: regexp.MustCompile(`(?i)a branch named '(?P<branch>[^']+)' already exists`),
+		cause:   "There is already a branch called '${branch}'.",
+		fix:     []string{"git switch ${branch}        # use the existing branch", "git branch -m ${branch} ${branch}-old   # or rename it first"},
+		topics:  []topicRef{gitCommandTopic("branch")},
+	},
+	{
+		tool:    "git",
+		title:   "Remote already exists",
+		pattern: regexp.MustCompile(`(?i)remote (?P<name>\S+) already exists`),
+		cause:   "A remote named '${name}' is already configured.",
+		fix:     []string{"git remote -v                        # see where it points", "git remote set-url ${name} <new-url>"},
+		topics:  []topicRef{gitCommandTopic("remote")},
+	},
+	{
+		tool:    "git",
+		title:   "SSH key rejected",
+		pattern: regexp.MustCompile(`(?i)Permission denied \(publickey\)`),
+		cause:   "The server did not accept any of your SSH keys, or you have none loaded.",
+		fix:     []string{"ssh -T git@github.com   # test the connection", "ssh-add ~/.ssh/id_ed25519", "# or switch the remote to HTTPS: git remote set-url origin https://..."},
+		topics:  []topicRef{gitCommandTopic("remote"), gitCommandTopic("clone")},
+	},
+	{
+		tool:    "git",
+		title:   "Authentication failed",
+		pattern: regexp.MustCompile(`(?i)Authentication failed for '(?P<url>[^']+)'`),
+		cause:   "The credentials for ${url} were rejected. Many hosts no longer accept account passwords over HTTPS.",
+		fix:     []string{"# create a personal access token and use it as the password", "git credential reject   # forget the stored credentials"},
+		topics:  []topicRef{gitCommandTopic("remote"), gitCommandTopic("push")},
+	},
+	{
+		tool:    "git",
+		title:   "Repository not found",
+		pattern: regexp.MustCompile(`(?i)repository '?(?P<url>[^'\s]*)'? not found`),
+		cause:   "The URL is wrong, the repository was renamed or deleted, or you have no access to it.",
+		fix:     []string{"git remote -v", "git remote set-url origin <correct-url>"},
+		topics:  []topicRef{gitCommandTopic("remote"), gitCommandTopic("clone")},
+	},
+	{
+		tool:    "git",
+		title:   "Another git process holds the lock",
+		pattern: regexp.MustCompile(`(?i)Unable to create '(?P<path>[^']+\.lock)': File exists`),
+		cause:   "Git found ${path}, left behind by a git process that is still running or crashed.",
+		fix:     []string{"# make sure no other git command is running, then", "rm '${path}'"},
+		topics:  []topicRef{gitCommandTopic("status")},
+	},
+	{
+		tool:    "git",
+		title:   "Author identity unknown",
+		pattern: regexp.MustCompile(`(?i)Please tell me who you are|Author identity unknown`),
+		cause:   "Git needs a name and email to record as the author of commits.",
+		fix:     []string{`git config --global user.name "Your Name"`, `git config --global user.email "you@example.com"`},
+		topics:  []topicRef{gitCommandTopic("commit")},
+	},
+	{
+		tool:    "git",
+		title:   "Nothing to commit",
+		pattern: regexp.MustCompile(`(?i)nothing (?:added )?to commit`),
+		cause:   "No changes are staged. Changed files must be added before committing.",
+		fix:     []string{"git status", "git add <paths>", "git commit"},
+		topics:  []topicRef{gitCommandTopic("status"), gitCommandTopic("add")},
+	},
+}
+
+var dockerErrorPatterns = []errorPattern{
+	{
+		tool:    "docker",
+		title:   "Docker daemon is not running",
+		pattern: regexp.MustCompile(`(?i)Cannot connect to the Docker daemon at (?P<socket>\S+?)\.? Is the docker daemon running\?`),
+		cause:   "The docker CLI could not reach the daemon at ${socket}. The daemon is stopped or DOCKER_HOST points elsewhere.",
+		fix:     []string{"sudo systemctl start docker   # Linux", "# or start Docker Desktop"},
+		topics:  []topicRef{dockerCommandTopic("run")},
+	},
+	{
+		tool:    "docker",
+		title:   "Permission denied on the Docker daemon socket",
+		pattern: regexp.MustCompile(`(?i)permission denied while trying to connect to the Docker daemon socket at (?P<socket>[^\s:]+(?::/{2}[^\s:]+)?)`),
+		cause:   "Your user may not access ${socket}. Only root and members of the 'docker' group can talk to the daemon.",
+		fix:     []string{"sudo usermod -aG docker $$USER", "newgrp docker   # or log out and back in"},
+		topics:  []topicRef{dockerCommandTopic("run")},
+	},
+	{
+		tool:    "docker",
+		title:   "Port is already allocated",
+		pattern: regexp.MustCompile(`(?i)(?:Bind for|listen tcp4?) \S*?:(?P<port>\d+)(?: failed: port is already allocated|: bind: address already in use)`),
+		cause:   "Host port ${port} is already published by another container or used by a process on the host, so it cannot be published again.",
+		fix: []string{
+			"docker ps --filter publish=${port}   # find the container using it",
+			"sudo lsof -i :${port}   # or the host process using it",
+			"docker run -p <other-port>:<container-port> ...   # or publish a different host port",
+		},
+		topics: []topicRef{dockerCommandTopic("run"), dockerAdvancedTopic("network")},
+	},
+	{
+		tool:    "docker",
+		title:   "No space left on device",
+		pattern: regexp.MustCompile(`(?i)no space left on device`),
+		cause:   "The disk holding Docker's data (usually /var/lib/docker) is full. Unused images, build cache, stopped containers and volumes add up quickly.",
+		fix: []string{
+			"docker system df          # see what uses the space",
+			"docker system prune       # remove stopped containers, unused networks and dangling images",
+			"docker builder prune      # clear the build cache",
+			"docker volume prune       # remove unused volumes (deletes their data)",
+		},
+		topics: []topicRef{dockerAdvancedTopic("volume"), dockerCommandTopic("build")},
+	},
+	{
+		tool:    "docker",
+		title:   "Manifest unknown",
+		pattern: regexp.MustCompile(`(?i)manifest for (?P<image>\S+) not found: manifest unknown|manifest unknown`),
+		cause:   "The registry does not have the tag or digest you asked for (${image}). The tag is misspelled, was never pushed, has been deleted, or was not built for this platform.",
+		fix:     []string{"# check the available tags on the registry", "docker pull <repository>:<existing-tag>", "docker push <repository>:<tag>   # if it is your image and was never pushed"},
+		topics:  []topicRef{dockerCommandTopic("push"), dockerCommandTopic("run")},
+	},
+	{
+		tool:    "docker",
+		title:   "Pull access denied",
+		pattern: regexp.MustCompile(`(?i)pull access denied for (?P<image>[^,\s]+), repository does not exist or may require 'docker login'`),
+		cause:   "Either '${image}' does not exist on the registry or it is private and you are not logged in.",
+		fix:     []string{"docker login", "docker pull ${image}"},
+		topics:  []topicRef{dockerCommandTopic("push"), dockerCommandTopic("run")},
+	},
+	{
+		tool:    "docker",
+		title:   "Push access denied",
+		pattern: regexp.MustCompile(`(?i)denied: requested access to the resource is denied`),
+		cause:   "You are not logged in to the registry, or the image is not tagged under a namespace you can push to.",
+		fix:     []string{"docker login", "docker tag my-image <your-user>/my-image:latest", "docker push <your-user>/my-image:latest"},
+		topics:  []topicRef{dockerCommandTopic("push")},
+	},
+	{
+		tool:    "docker",
+		title:   "Invalid image reference",
+		pattern: regexp.MustCompile(`(?i)invalid reference format`),
+		cause:   "The image name is not a valid reference. Names must be lowercase and may only contain letters, digits, '.', '_', '-' and '/'.",
+		fix:     []string{"docker build -t my-image:1.0 ."},
+		topics:  []topicRef{dockerCommandTopic("build"), dockerCommandTopic("push")},
+	},
+	{
+		tool:    "docker",
+		title:   "Container name already in use",
+		pattern: regexp.MustCompile(`(?i)The container name "/?(?P<name>[^"]+)" is already in use by container "(?P<id>[0-9a-f]+)"`),
+		cause:   "A container called '${name}' already exists (ID ${id}), possibly stopped.",
+		fix:     []string{"docker rm -f ${name}          # remove the old container", "docker run --name ${name}-2 ...   # or choose another name"},
+		topics:  []topicRef{dockerCommandTopic("run")},
+	},
+	{
+		tool:    "docker",
+		title:   "Bind mount source does not exist",
+		pattern: regexp.MustCompile(`(?i)bind source path does not exist: (?P<path>\S+)`),
+		cause:   "The host path ${path} given to -v or --mount does not exist. Unlike -v, --mount never creates it for you.",
+		fix:     []string{"mkdir -p ${path}"},
+		topics:  []topicRef{dockerCommandTopic("run"), dockerAdvancedTopic("volume")},
+	},
+	{
+		tool:    "docker",
+		title:   "Volume is in use",
+		pattern: regexp.MustCompile(`(?i)remove (?P<volume>\S+): volume is in use - \[(?P<containers>[^\]]+)\]`),
+		cause:   "Volume '${volume}' is still attached to container(s) ${containers}, even if they are stopped.",
+		fix:     []string{"docker ps -a --filter volume=${volume}   # containers using it", "docker rm <container>", "docker volume rm ${volume}"},
+		topics:  []topicRef{dockerAdvancedTopic("volume")},
+	},
+	{
+		tool:    "docker",
+		title:   "Network not found",
+		pattern: regexp.MustCompile(`(?i)network (?P<network>\S+) not found`),
+		cause:   "No network called '${network}' exists on this host.",
+		fix:     []string{"docker network ls", "docker network create ${network}"},
+		topics:  []topicRef{dockerAdvancedTopic("network")},
+	},
+	{
+		tool:    "docker",
+		title:   "Network has active endpoints",
+		pattern: regexp.MustCompile(`(?i)network (?P<network>\S+) (?:id \S+ )?has active endpoints`),
+		cause:   "Containers are still connected to '${network}', so it cannot be removed.",
+		fix:     []string{"docker network inspect ${network}   # list connected containers", "docker network disconnect ${network} <container>", "docker network rm ${network}"},
+		topics:  []topicRef{dockerAdvancedTopic("network")},
+	},
+	{
+		tool:    "docker",
+		title:   "Dockerfile not found",
+		pattern: regexp.MustCompile(`(?i)(?:open|lstat) (?P<path>\S*Dockerfile\S*): no such file or directory`),
+		cause:   "docker build could not find ${path}. By default it looks for a file named Dockerfile at the root of the build context.",
+		fix:     []string{"docker build -f path/to/Dockerfile .   # name the Dockerfile explicitly"},
+		topics:  []topicRef{dockerCommandTopic("build")},
+	},
+	{
+		tool:    "docker",
+		title:   "Executable not found in the container",
+		pattern: regexp.MustCompile(`(?i)exec: "(?P<binary>[^"]+)": executable file not found in \$PATH`),
+		cause:   "The image has no '${binary}' on its PATH. Slim images often lack bash or common tools.",
+		fix:     []string{"docker run -it <image> sh   # use sh instead of bash", "# or install the tool in the Dockerfile"},
+		topics:  []topicRef{dockerCommandTopic("run"), dockerCommandTopic("build")},
+	},
+	{
+		tool:    "docker",
+		title:   "Exec format error",
+		pattern: regexp.MustCompile(`(?i)exec format error`),
+		cause:   "The image was built for a different CPU architecture than this host (for example arm64 on amd64).",
+		fix:     []string{"docker run --platform linux/amd64 ...", "docker build --platform linux/amd64,linux/arm64 ...   # with buildx"},
+		topics:  []topicRef{dockerCommandTopic("run"), dockerCommandTopic("build")},
+	},
+	{
+		tool:    "docker",
+		title:   "Not a swarm manager",
+		pattern: regexp.MustCompile(`(?i)This node is not a swarm manager`),
+		cause:   "Swarm commands such as 'docker service' and 'docker stack' must run on a manager node.",
+		fix:     []string{"docker swarm init   # make this host a manager of a new swarm"},
+		topics:  []topicRef{dockerAdvancedTopic("swarm")},
+	},
+}
+
+var kubectlErrorPatterns = []errorPattern{
+	{
+		tool:    "kubectl",
+		title:   "Cannot reach the cluster",
+		pattern: regexp.MustCompile(`(?i)The connection to the server (?P<server>\S+?) was refused`),
+		cause:   "kubectl could not reach the API server at ${server}. The cluster is down, or kubectl uses the wrong context; localhost:8080 means no kubeconfig was found at all.",
+		fix:     []string{"kubectl config current-context", "kubectl config get-contexts", "kubectl config use-context <name>"},
+		topics:  []topicRef{kubectlAdvancedTopic("contexts")},
+	},
+	{
+		tool:    "kubectl",
+		title:   "Not logged in to the cluster",
+		pattern: regexp.MustCompile(`(?i)You must be logged in to the server \(Unauthorized\)`),
+		cause:   "The API server rejected your credentials. The token or client certificate in the kubeconfig has expired, or belongs to another cluster.",
+		fix:     []string{"kubectl config view --minify   # see which user the context uses", "# refresh the credentials, e.g. with your cloud provider's get-credentials command"},
+		topics:  []topicRef{kubectlAdvancedTopic("contexts")},
+	},
+	{
+		tool:    "kubectl",
+		title:   "Forbidden",
+		pattern: regexp.MustCompile(`(?i)User "(?P<user>[^"]+)" cannot (?P<verb>\w+) resource "(?P<resource>[^"]+)"(?: in API group "[^"]*")?(?: in the namespace "(?P<namespace>[^"]+)")?`),
+		cause:   "You are logged in as ${user}, but no Role or ClusterRole bound to you allows '${verb}' on ${resource} in namespace ${namespace}.",
+		fix:     []string{"kubectl auth can-i ${verb} ${resource} -n ${namespace}", "kubectl auth can-i --list -n ${namespace}", "# ask a cluster admin for a RoleBinding"},
+		topics:  []topicRef{kubectlAdvancedTopic("rbac")},
+	},
+	{
+		tool:    "kubectl",
+		title:   "Resource not found",
+		pattern: regexp.MustCompile(`(?i)Error from server \(NotFound\): (?P<resource>\S+) "(?P<name>[^"]+)" not found`),
+		cause:   "There is no ${resource} called '${name}' in the namespace kubectl looked in. It may live in another namespace, or the context points at another cluster.",
+		fix:     []string{"kubectl get ${resource} -A | grep ${name}", "kubectl config current-context"},
+		topics:  []topicRef{kubectlCommandTopic("get"), kubectlAdvancedTopic("contexts")},
+	},
+	{
+		tool:    "kubectl",
+		title:   "Image cannot be pulled",
+		pattern: regexp.MustCompile(`ImagePullBackOff|ErrImagePull`),
+		cause:   "The node could not pull the image of a container: the name or tag is wrong, or the registry is private and the pod has no imagePullSecrets.",
+		fix:     []string{"kubectl describe pod <pod>   # the Events show the exact pull error"},
+		topics:  []topicRef{kubectlCommandTopic("describe")},
+	},
+	{
+		tool:    "kubectl",
+		title:   "Container keeps crashing",
+		pattern: regexp.MustCompile(`CrashLoopBackOff`),
+		cause:   "The container starts and exits again, so Kubernetes restarts it with growing delays. The reason is in its output from the run that crashed.",
+		fix:     []string{"kubectl logs <pod> --previous", "kubectl describe pod <pod>   # exit code, OOMKilled, failing probes"},
+		topics:  []topicRef{kubectlCommandTopic("logs"), kubectlCommandTopic("describe")},
+	},
+	{
+		tool:    "kubectl",
+		title:   "Unknown context",
+		pattern: regexp.MustCompile(`(?i)no context exists with the name: "?(?P<context>[^"\s]+)"?`),
+		cause:   "Your kubeconfig has no context called '${context}'.",
+		fix:     []string{"kubectl config get-contexts"},
+		topics:  []topicRef{kubectlAdvancedTopic("contexts")},
+	},
+}
+
+func errorPatterns() []errorPattern {
+	patterns := append([]errorPattern{}, gitErrorPatterns...)
+	patterns = append(patterns, dockerErrorPatterns...)
+	return append(patterns, kubectlErrorPatterns...)
+}
+
+// matchErrors returns every known error found in text.
+func matchErrors(text string) []errorMatch {
+	var matches []errorMatch
+	for _, p := range errorPatterns() {
+		m := p.pattern.FindStringSubmatchIndex(text)
+		if m == nil {
+			continue
+		}
+		expand := func(template string) string {
+			// Groups that did not take part in the match read as "<name>".
+			for i, name := range p.pattern.SubexpNames() {
+				if name != "" && m[2*i] < 0 {
+					template = strings.ReplaceAll(template, "${"+name+"}", "<"+name+">")
+				}
+			}
+			return string(p.pattern.ExpandString(nil, template, text, m))
+		}
+		match := errorMatch{pattern: p, cause: expand(p.cause)}
+		for _, f := range p.fix {
+			match.fix = append(match.fix, expand(f))
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+func printErrorMatch(m errorMatch) {
+	fmt.Printf("- %s\n", m.pattern.title)
+	fmt.Println(m.cause)
+	if len(m.fix) > 0 {
+		fmt.Print("\n")
+		fmt.Println("How to fix it:")
+		for _, f := range m.fix {
+			fmt.Println("$ " + f)
+		}
+	}
+	if len(m.pattern.topics) > 0 {
+		fmt.Print("\n")
+		fmt.Println("See:")
+		for _, t := range m.pattern.topics {
+			fmt.Println("  " + t.String())
+		}
+	}
+}
+
+// stdinIsPiped reports whether stdin is a pipe or file rather than a
+// terminal.
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
@@ -20,9 +20,20 @@ For example:
 - Giving extensive information about advanced git features`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) > 0 {
-			fmt.Println(`Git command has no subcommands. Please provide one of the following flags:
+			fmt.Printf(`Unknown Git subcommand '%s'. Please use one of the following subcommands:
+config
+conflict
+format
+ignore
+objects
+output
+recover
+show
+
+Or provide one of the following flags:
 --command
---advanced`)
+--advanced
+`, args[0])
 			return
 		}
 
@@ -57,10 +68,19 @@ For example:
 }
 
 func init() {
-	gitCmd.Flags().StringVarP(&commandFlag, "command", "c", "", "Specify a Git command to explain")
+	gitCmd.Flags().StringVarP(&commandFlag, "command", "c", "", "Specify a Git command or alias to explain")
 	gitCmd.Flags().StringVarP(&advancedFlag, "advanced", "a", "", "Explain advanced Git concepts")
 
-	gitCmd.SetUsageTemplate(usageTemplate())
+	// Subcommands such as recover and show keep cobra's usage, which lists
+	// their own flags; only gitCmd itself gets the topic overview.
+	defaultUsage := rootCmd.UsageFunc()
+	gitCmd.SetUsageFunc(func(c *cobra.Command) error {
+		if c != gitCmd {
+			return defaultUsage(c)
+		}
+		fmt.Fprint(c.OutOrStderr(), usageTemplate())
+		return nil
+	})
 }
 
 func explainGitCommand(command string) {
@@ -81,6 +101,8 @@ func explainGitCommand(command string) {
 		fmt.Println("The 'git status' command shows the status of changes as untracked, modified, or staged.")
 		fmt.Println("Example: git status")
 		fmt.Println("This command displays the current state of the working directory and staging area.")
+		fmt.Print("\n")
+		fmt.Println("To have each line of its short or porcelain output explained:\n$ git status --porcelain=v2 --branch | explain git output")
 	case "branch":
 		fmt.Println("The 'git branch' command lists, creates, or deletes branches.")
 		fmt.Println("Example: git branch feature-branch")
@@ -101,6 +123,9 @@ func explainGitCommand(command string) {
 		fmt.Println("The 'git log' command displays the commit history of the repository.")
 		fmt.Println("Example: git log")
 		fmt.Println("This command shows a log of commits, including commit messages and authors.")
+		fmt.Print("\n")
+		fmt.Println("To have the branch lines and decorations of a graph explained:\n$ git log --graph --oneline --decorate -20 | explain git output")
+		fmt.Printf("To label the placeholders of a custom format and preview it:\n$ explain git format '%s'\n", "%h %an %ar %s %d")
 	case "clone":
 		fmt.Println("The 'git clone' command clones a repository into a new directory.")
 		fmt.Println("Example: git clone https://github.com/example/repo.git")
@@ -122,8 +147,14 @@ func explainGitCommand(command string) {
 		fmt.Println("Example: git tag -a v1.0 -m 'Version 1.0'")
 		fmt.Println("This command creates an annotated tag 'v1.0' with a message.")
 	default:
+		if alias, ok := resolveGitAlias(command); ok {
+			printGitAlias(alias)
+			return
+		}
 		fmt.Printf("Explanation for '%s' is not available. Try another Git command.\n", command)
 	}
+
+	printRiskWarnings(gitCommandTopic(command))
 }
 
 func explainAdvancedGitConcepts(command string) {
@@ -197,6 +228,9 @@ git reflog show <branch> Display the reflog for a specific branch`)
 
 To view the reflog for the current branch:
 $ git reflog`)
+		fmt.Print("\n")
+		fmt.Println(`To browse the reflog of the current repository and restore an entry:
+$ explain git recover`)
 	case "hooks":
 		fmt.Println("- Git hooks are scripts that run automatically before or after certain Git commands. They allow you to customize and automate processes in your Git workflow.")
 		fmt.Print("\n")
@@ -259,11 +293,14 @@ $ git bisect start`)
 	default:
 		fmt.Printf("Explanation for '%s' is not available. Try another advanced Git concept.\n", command)
 	}
+
+	printRiskWarnings(gitAdvancedTopic(command))
 }
 
 func usageTemplate() string {
 	return `Usage:
   explain git [flags]
+  explain git [command]
 
 Flags:
   -a, --advanced string   Explain advanced Git concepts
@@ -281,5 +318,15 @@ Available Commands:
 
 Available Advanced Topics:
   rebase, cherry-pick, submodule, stash, reflog, hooks, gitflow, revert, filter-branch, bisect
+
+Available Subcommands:
+  config      Explain configuration keys and audit your configuration
+  conflict    Explain conflict markers and which side is ours or theirs
+  format      Label the placeholders of a git log --format string
+  ignore      Explain .gitignore patterns and check why a file is ignored
+  objects     Walk the objects behind a commit and how they are stored
+  output      Annotate git status, git diff or git log --graph output line by line
+  recover     List recent reflog entries and restore a lost state
+  show        Summarize a commit in plain language
 `
 }
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gitAlias is a git alias resolved through any aliases it refers to.
+type gitAlias struct {
+	name      string
+	expansion string           // what git runs instead, without the leading "git"
+	chain     []gitConfigEntry // the alias.* entries used, outermost first
+	shell     bool             // the expansion starts with "!" and runs in a shell
+}
+
+// resolveGitAlias looks name up in the alias section of the git
+// configuration. Like git, aliases never shadow the commands explain knows
+// about, and an alias may expand to another alias.
+func resolveGitAlias(name string) (gitAlias, bool) {
+	alias := gitAlias{name: name}
+	seen := map[string]bool{}
+	var rest []string
+
+	for !seen[name] && topicSummary(gitCommandTopic(name)) == "" {
+		seen[name] = true
+		entry, ok := currentGitConfig().get("alias." + name)
+		if !ok {
+			break
+		}
+		alias.chain = append(alias.chain, entry)
+
+		if strings.HasPrefix(entry.value, "!") {
+			alias.shell = true
+			alias.expansion = strings.Join(append([]string{entry.value}, rest...), " ")
+			return alias, true
+		}
+		words := splitCommandLine(entry.value)
+		if len(words) == 0 {
+			// Git refuses to run an empty alias.
+			return gitAlias{}, false
+		}
+		name = words[0]
+		rest = append(words[1:], rest...)
+		alias.expansion = strings.Join(append([]string{name}, quoteWords(rest)...), " ")
+	}
+	return alias, len(alias.chain) > 0
+}
+
+// expandGitAlias rewrites a git command line whose subcommand is an alias
+// into the words git actually runs.
+func expandGitAlias(words []string) ([]string, string, bool) {
+	if len(words) < 2 || words[0] != "git" {
+		return nil, "", false
+	}
+	alias, ok := resolveGitAlias(words[1])
+	if !ok || alias.shell {
+		return nil, "", false
+	}
+	expanded := append([]string{"git"}, splitCommandLine(alias.expansion)...)
+	return append(expanded, words[2:]...), alias.name, true
+}
+
+func printGitAlias(alias gitAlias) {
+	fmt.Printf("'git %s' is an alias, not a built-in Git command.\n", alias.name)
+	for _, entry := range alias.chain {
+		fmt.Printf("  %-32s %s\n", entry.key+" = "+entry.value, entry.location())
+	}
+	fmt.Print("\n")
+
+	if alias.shell {
+		fmt.Printf("It runs a shell command: %s\n", strings.TrimPrefix(alias.expansion, "!"))
+		fmt.Println("The leading '!' makes Git run the rest with the shell, from the top of the working tree. Arguments given to the alias are appended to it.")
+		for _, segment := range splitShellList(strings.TrimPrefix(alias.expansion, "!")) {
+			if c, ok := parseCommandLine(strings.TrimSpace(segment)); ok {
+				fmt.Print("\n")
+				printCommandBreakdown(c)
+			}
+		}
+		return
+	}
+
+	fmt.Printf("It expands to: git %s\n", alias.expansion)
+	fmt.Print("\n")
+	c, ok := parseCommandLine("git " + alias.expansion)
+	if !ok {
+		if words := strings.Fields(alias.expansion); len(words) > 0 {
+			fmt.Printf("Explanation for 'git %s' is not available.\n", words[0])
+		}
+		return
+	}
+	printCommandBreakdown(c)
+}
+
+// quoteWords quotes words that a shell would split, for display.
+func quoteWords(words []string) []string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = shellQuote(w)
+	}
+	return quoted
+}
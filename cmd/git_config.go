@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// gitConfigEntry is one "key = value" line of a git configuration file.
+// Keys are normalised to "section.name" or "section.subsection.name" with
+// the section and name lowercased, as git compares them.
+type gitConfigEntry struct {
+	key   string
+	value string
+	file  string
+	line  int
+}
+
+// gitConfig holds the entries of every configuration file in the order git
+// reads them, so later entries override earlier ones.
+type gitConfig struct {
+	entries []gitConfigEntry
+	files   []string
+}
+
+var (
+	gitConfigOnce   sync.Once
+	gitConfigLoaded *gitConfig
+)
+
+// currentGitConfig loads the system, global and repository configuration
+// once per run.
+func currentGitConfig() *gitConfig {
+	gitConfigOnce.Do(func() {
+		repo, _ := openGitRepo()
+		gitConfigLoaded = loadGitConfig(repo)
+	})
+	return gitConfigLoaded
+}
+
+// gitConfigFiles lists the configuration files git reads, lowest priority
+// first. Files that do not exist are skipped when loading.
+func gitConfigFiles(repo *gitRepo) []string {
+	files := []string{"/etc/gitconfig"}
+	if system := os.Getenv("GIT_CONFIG_SYSTEM"); system != "" {
+		files[0] = system
+	}
+
+	home, _ := os.UserHomeDir()
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg == "" && home != "" {
+		xdg = filepath.Join(home, ".config")
+	}
+	if global := os.Getenv("GIT_CONFIG_GLOBAL"); global != "" {
+		files = append(files, global)
+	} else {
+		if xdg != "" {
+			files = append(files, filepath.Join(xdg, "git", "config"))
+		}
+		if home != "" {
+			files = append(files, filepath.Join(home, ".gitconfig"))
+		}
+	}
+
+	if repo != nil {
+		files = append(files, repo.path("config"))
+	}
+	return files
+}
+
+func loadGitConfig(repo *gitRepo) *gitConfig {
+	c := &gitConfig{}
+	for _, file := range gitConfigFiles(repo) {
+		c.readFile(file, repo, 0)
+	}
+	return c
+}
+
+// readFile parses a configuration file and follows its [include] and
+// [includeIf] directives.
+func (c *gitConfig) readFile(path string, repo *gitRepo, depth int) {
+	if depth > 10 {
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	c.files = append(c.files, path)
+
+	var (
+		section string
+		lineNo  int
+		pending string
+		startNo int
+		scanner = bufio.NewScanner(f)
+	)
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if pending != "" {
+			line = pending + line
+		} else {
+			startNo = lineNo
+		}
+		// A backslash at the end of a line continues the value.
+		if strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") {
+			pending = strings.TrimSuffix(line, "\\")
+			continue
+		}
+		pending = ""
+
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			var rest string
+			section, rest = parseGitConfigSection(line)
+			line = strings.TrimSpace(rest)
+			if line == "" || line[0] == '#' || line[0] == ';' {
+				continue
+			}
+		}
+
+		name, value := parseGitConfigLine(line)
+		if name == "" || section == "" {
+			continue
+		}
+		key := section + "." + strings.ToLower(name)
+		c.entries = append(c.entries, gitConfigEntry{key: key, value: value, file: path, line: startNo})
+
+		if include := includedGitConfig(section, strings.ToLower(name), value, path, repo); include != "" {
+			c.readFile(include, repo, depth+1)
+		}
+	}
+}
+
+// parseGitConfigSection parses a section header such as [core],
+// [remote "origin"] or the legacy [branch.main] and returns the normalised
+// section and whatever follows the closing bracket.
+func parseGitConfigSection(line string) (string, string) {
+	end := strings.LastIndex(line, "]")
+	if end < 0 {
+		return "", ""
+	}
+	header, rest := strings.TrimSpace(line[1:end]), line[end+1:]
+
+	if name, sub, ok := strings.Cut(header, " "); ok {
+		sub = strings.TrimSpace(sub)
+		sub = strings.TrimSuffix(strings.TrimPrefix(sub, `"`), `"`)
+		sub = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(sub)
+		return strings.ToLower(name) + "." + sub, rest
+	}
+	if name, sub, ok := strings.Cut(header, "."); ok {
+		return strings.ToLower(name) + "." + strings.ToLower(sub), rest
+	}
+	return strings.ToLower(header), rest
+}
+
+// parseGitConfigLine splits "name = value" and unquotes the value. A name
+// without a value is a boolean set to true.
+func parseGitConfigLine(line string) (string, string) {
+	name, raw, ok := strings.Cut(line, "=")
+	name = strings.TrimSpace(name)
+	if !ok {
+		if i := strings.IndexAny(name, "#;"); i >= 0 {
+			name = strings.TrimSpace(name[:i])
+		}
+		return name, "true"
+	}
+
+	var (
+		value   strings.Builder
+		quoted  bool
+		spaces  string
+		started bool
+	)
+	raw = strings.TrimSpace(raw)
+	for i := 0; i < len(raw); i++ {
+		ch := raw[i]
+		switch {
+		case ch == '\\' && i+1 < len(raw):
+			i++
+			value.WriteString(spaces)
+			spaces = ""
+			switch raw[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case 'b':
+				value.WriteByte('\b')
+			default:
+				value.WriteByte(raw[i])
+			}
+		case ch == '"':
+			quoted = !quoted
+			started = true
+		case !quoted && (ch == '#' || ch == ';'):
+			return name, value.String()
+		case !quoted && (ch == ' ' || ch == '\t'):
+			// Whitespace between words is kept, trailing whitespace is not.
+			if started {
+				spaces += string(ch)
+			}
+		default:
+			value.WriteString(spaces)
+			spaces = ""
+			value.WriteByte(ch)
+			started = true
+		}
+	}
+	return name, value.String()
+}
+
+// includedGitConfig returns the file to read for an include.path or a
+// matching includeIf.<condition>.path entry.
+func includedGitConfig(section, name, value, from string, repo *gitRepo) string {
+	if name != "path" {
+		return ""
+	}
+	if section != "include" {
+		condition, ok := strings.CutPrefix(section, "includeif.")
+		if !ok || !gitIncludeConditionHolds(condition, from, repo) {
+			return ""
+		}
+	}
+	return expandGitConfigPath(value, filepath.Dir(from))
+}
+
+// gitIncludeConditionHolds evaluates the gitdir: and onbranch: conditions
+// of [includeIf]. Other conditions are treated as false.
+func gitIncludeConditionHolds(condition, from string, repo *gitRepo) bool {
+	if repo == nil {
+		return false
+	}
+	kind, pattern, ok := strings.Cut(condition, ":")
+	if !ok {
+		return false
+	}
+	switch kind {
+	case "gitdir", "gitdir/i":
+		pattern = gitdirPattern(pattern, from)
+		dirs := []string{repo.dir}
+		if real, err := filepath.EvalSymlinks(repo.dir); err == nil && real != repo.dir {
+			dirs = append(dirs, real)
+		}
+		if kind == "gitdir/i" {
+			pattern = strings.ToLower(pattern)
+		}
+		re := globRegexp(pattern)
+		for _, dir := range dirs {
+			dir = filepath.ToSlash(dir)
+			if kind == "gitdir/i" {
+				dir = strings.ToLower(dir)
+			}
+			if re.MatchString(dir) {
+				return true
+			}
+		}
+		return false
+	case "onbranch":
+		branch := repo.currentBranch()
+		if strings.HasSuffix(pattern, "/") {
+			return strings.HasPrefix(branch, pattern)
+		}
+		matched, _ := filepath.Match(pattern, branch)
+		return matched
+	}
+	return false
+}
+
+// gitdirPattern turns the pattern of a gitdir: condition into a glob the
+// way git does: "~/" is the home directory, "./" the directory of the
+// including file, a pattern that is not anchored matches at any depth, and
+// a trailing "/" matches everything below it.
+func gitdirPattern(pattern, from string) string {
+	pattern = filepath.ToSlash(pattern)
+	if rest, ok := strings.CutPrefix(pattern, "./"); ok {
+		pattern = filepath.ToSlash(filepath.Dir(from)) + "/" + rest
+	} else if rest, ok := strings.CutPrefix(pattern, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			pattern = filepath.ToSlash(home) + "/" + rest
+		}
+	}
+	if !strings.HasPrefix(pattern, "/") && !filepath.IsAbs(filepath.FromSlash(pattern)) {
+		pattern = "**/" + pattern
+	}
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return pattern
+}
+
+// expandGitConfigPath resolves "~/" and paths relative to the including
+// file.
+func expandGitConfigPath(path, base string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Join(base, path)
+	}
+	return path
+}
+
+// get returns the effective value of key, i.e. the last one set.
+func (c *gitConfig) get(key string) (gitConfigEntry, bool) {
+	key = normalizeGitConfigKey(key)
+	for i := len(c.entries) - 1; i >= 0; i-- {
+		if c.entries[i].key == key {
+			return c.entries[i], true
+		}
+	}
+	return gitConfigEntry{}, false
+}
+
+// normalizeGitConfigKey lowercases the section and name of a key while
+// keeping the subsection as written.
+func normalizeGitConfigKey(key string) string {
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+	if first < 0 {
+		return strings.ToLower(key)
+	}
+	if first == last {
+		return strings.ToLower(key)
+	}
+	return strings.ToLower(key[:first]) + key[first:last] + strings.ToLower(key[last:])
+}
+
+func (e gitConfigEntry) location() string {
+	return fmt.Sprintf("%s:%d", e.file, e.line)
+}
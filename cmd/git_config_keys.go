@@ -0,0 +1,681 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"runtime"
+	"strings"
+)
+
+var configAudit bool
+
+var gitConfigCmd = &cobra.Command{
+	Use:   "config [key]",
+	Short: "Explains Git configuration keys and audits your configuration",
+	Long: `This command explains what a Git configuration key does, which values it accepts
+and what Git uses when it is not set, along with the value currently in effect.
+With --audit it reads every configuration file Git would read and explains each
+key that is set, flagging unusual or risky values.
+For example:
+
+- explain git config pull.rebase
+- explain git config core.autocrlf
+- explain git config --audit`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if configAudit {
+			auditGitConfig(currentGitConfig())
+			return
+		}
+		if len(args) == 0 {
+			listGitConfigKeys()
+			return
+		}
+
+		k, ok := findGitConfigKey(args[0])
+		if !ok {
+			fmt.Printf("Explanation for '%s' is not available.\n", args[0])
+			if related := relatedGitConfigKeys(args[0]); len(related) > 0 {
+				fmt.Printf("Keys explained in the same section: %s\n", strings.Join(related, ", "))
+			}
+			return
+		}
+		printGitConfigKey(k, args[0])
+	},
+}
+
+func init() {
+	gitCmd.AddCommand(gitConfigCmd)
+
+	gitConfigCmd.Flags().BoolVar(&configAudit, "audit", false, "Explain every key set in your configuration files and flag risky values")
+}
+
+// gitConfigKey describes a configuration key. A "*" in key stands for a
+// subsection such as a remote or branch name.
+type gitConfigKey struct {
+	key          string
+	summary      string
+	values       []string
+	defaultValue string
+	check        func(value string) string // returns a warning for risky values
+}
+
+var gitConfigKeys = []gitConfigKey{
+	{
+		key:          "user.name",
+		summary:      "The name recorded as author and committer of your commits.",
+		values:       []string{"Any text, usually your full name."},
+		defaultValue: "Guessed from the system account, with a warning on every commit.",
+	},
+	{
+		key:          "user.email",
+		summary:      "The email address recorded in your commits. Hosting services use it to link commits to your account.",
+		values:       []string{"An email address. Use a per-repository value or [includeIf] for work and personal repositories."},
+		defaultValue: "Guessed from the user and host name, which is rarely a real address.",
+	},
+	{
+		key:          "user.signingKey",
+		summary:      "The key used to sign commits and tags.",
+		values:       []string{"A GPG key id, or a path to an SSH public key when gpg.format is ssh."},
+		defaultValue: "The GPG key matching user.name and user.email.",
+	},
+	{
+		key:     "core.autocrlf",
+		summary: "Converts line endings between CRLF (Windows) and LF when files are checked out and committed.",
+		values: []string{
+			"true: commit LF, check out CRLF. Meant for Windows.",
+			"input: convert CRLF to LF on commit, never on checkout. Meant for macOS and Linux.",
+			"false: store and check out files exactly as they are.",
+		},
+		defaultValue: "false (Git for Windows sets true in its system configuration).",
+		check: func(value string) string {
+			if gitConfigBool(value) == "true" && runtime.GOOS != "windows" {
+				return "CRLF line endings are written to the working tree on a system that expects LF. Use 'input' or false, and prefer a .gitattributes file for per-project rules."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "core.eol",
+		summary:      "The line ending used in the working tree for files marked as text in .gitattributes.",
+		values:       []string{"lf", "crlf", "native: the line ending of the current platform."},
+		defaultValue: "native",
+	},
+	{
+		key:          "core.fileMode",
+		summary:      "Whether a change to the executable bit counts as a change to the file.",
+		values:       []string{"true: track the executable bit.", "false: ignore permission changes."},
+		defaultValue: "true, except on file systems that cannot store it, where git init sets false.",
+		check: func(value string) string {
+			if gitConfigBool(value) == "false" {
+				return "Executable bits you change are not committed, so scripts may lose or never get their executable bit for others."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "core.ignoreCase",
+		summary:      "Whether Git treats file names that differ only in case as the same file.",
+		values:       []string{"true: for case-insensitive file systems.", "false: for case-sensitive file systems."},
+		defaultValue: "Detected by git init and git clone. Do not change it by hand.",
+		check: func(value string) string {
+			if gitConfigBool(value) == "true" && runtime.GOOS == "linux" {
+				return "Set to true on a case-sensitive file system. Renames that only change case can go unnoticed; let git init detect this value instead."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "core.bare",
+		summary:      "Whether the repository has no working tree, as on a server. Set by git init --bare.",
+		values:       []string{"true", "false"},
+		defaultValue: "Set by git init and git clone.",
+	},
+	{
+		key:          "core.repositoryFormatVersion",
+		summary:      "The version of the on-disk repository format. Set by Git; do not change it.",
+		values:       []string{"0", "1: when repository extensions are in use."},
+		defaultValue: "0",
+	},
+	{
+		key:          "core.logAllRefUpdates",
+		summary:      "Whether Git keeps a reflog of branch movements, which is what makes lost commits recoverable.",
+		values:       []string{"true: keep reflogs for branches and HEAD.", "always: keep reflogs for every ref, tags included.", "false: keep no reflogs."},
+		defaultValue: "true in repositories with a working tree, false in bare ones.",
+		check: func(value string) string {
+			if gitConfigBool(value) == "false" {
+				return "No reflog is kept, so commits lost by a reset or rebase cannot be recovered with 'explain git recover'."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "core.editor",
+		summary:      "The editor Git opens for commit messages, interactive rebases and tags.",
+		values:       []string{"A command, e.g. \"code --wait\" or vim."},
+		defaultValue: "$GIT_EDITOR, $VISUAL or $EDITOR, then vi.",
+	},
+	{
+		key:          "core.pager",
+		summary:      "The program used to page long output such as git log and git diff.",
+		values:       []string{"A command such as less or delta, or an empty value to disable paging."},
+		defaultValue: "$GIT_PAGER or $PAGER, then less.",
+	},
+	{
+		key:          "core.excludesFile",
+		summary:      "A global ignore file applied to every repository, on top of each .gitignore.",
+		values:       []string{"A path; ~/ is expanded to your home directory."},
+		defaultValue: "$XDG_CONFIG_HOME/git/ignore, or ~/.config/git/ignore.",
+	},
+	{
+		key:          "core.hooksPath",
+		summary:      "The directory Git looks in for hooks instead of .git/hooks.",
+		values:       []string{"A path, relative to the top of the working tree if not absolute."},
+		defaultValue: ".git/hooks",
+		check: func(value string) string {
+			return "Hooks run automatically on commit, push and checkout. Make sure you trust every script in " + value + "."
+		},
+	},
+	{
+		key:          "core.sshCommand",
+		summary:      "The ssh command Git uses for ssh:// and git@host: remotes, e.g. to pick a specific key.",
+		values:       []string{"A command such as \"ssh -i ~/.ssh/work_key\"."},
+		defaultValue: "$GIT_SSH_COMMAND, then ssh.",
+	},
+	{
+		key:          "init.defaultBranch",
+		summary:      "The name of the first branch created by git init.",
+		values:       []string{"A branch name, commonly main."},
+		defaultValue: "master, with a hint suggesting to configure it.",
+	},
+	{
+		key:     "pull.rebase",
+		summary: "Whether git pull rebases your local commits onto the fetched ones instead of merging.",
+		values: []string{
+			"false: merge, creating a merge commit when the branches diverged.",
+			"true: rebase local commits on top of the upstream branch.",
+			"merges: rebase, keeping local merge commits.",
+			"interactive: rebase interactively.",
+		},
+		defaultValue: "Unset. Since Git 2.33 a pull of diverged branches stops and asks you to choose.",
+	},
+	{
+		key:     "pull.ff",
+		summary: "Whether git pull may fast-forward, must fast-forward, or always creates a merge commit.",
+		values: []string{
+			"true: fast-forward when possible, merge otherwise.",
+			"only: refuse to pull unless it is a fast-forward.",
+			"false: always create a merge commit.",
+		},
+		defaultValue: "true",
+	},
+	{
+		key:     "push.default",
+		summary: "Which branch git push updates when no branch is named.",
+		values: []string{
+			"simple: push the current branch to its upstream of the same name.",
+			"current: push the current branch to a branch of the same name.",
+			"upstream: push the current branch to its upstream, whatever its name.",
+			"matching: push every local branch that has a remote branch of the same name.",
+			"nothing: refuse to push without an explicit branch.",
+		},
+		defaultValue: "simple",
+		check: func(value string) string {
+			if strings.EqualFold(value, "matching") {
+				return "'matching' pushes every branch with a same-named remote branch, including stale ones you did not mean to push."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "push.autoSetupRemote",
+		summary:      "Whether the first git push of a new branch sets its upstream, as if -u were given.",
+		values:       []string{"true", "false"},
+		defaultValue: "false",
+	},
+	{
+		key:          "push.followTags",
+		summary:      "Whether git push also pushes annotated tags that point at pushed commits.",
+		values:       []string{"true", "false"},
+		defaultValue: "false",
+	},
+	{
+		key:          "fetch.prune",
+		summary:      "Whether git fetch removes remote-tracking branches that no longer exist on the remote.",
+		values:       []string{"true", "false"},
+		defaultValue: "false",
+	},
+	{
+		key:          "rebase.autoStash",
+		summary:      "Whether git rebase stashes uncommitted changes before it starts and restores them afterwards.",
+		values:       []string{"true", "false"},
+		defaultValue: "false",
+	},
+	{
+		key:          "rebase.autoSquash",
+		summary:      "Whether interactive rebases move fixup! and squash! commits next to the commit they fix.",
+		values:       []string{"true", "false"},
+		defaultValue: "false",
+	},
+	{
+		key:          "rebase.updateRefs",
+		summary:      "Whether git rebase also moves other branches that point at the rebased commits, for stacked branches.",
+		values:       []string{"true", "false"},
+		defaultValue: "false",
+	},
+	{
+		key:          "merge.ff",
+		summary:      "Whether git merge fast-forwards when it can.",
+		values:       []string{"true: fast-forward when possible.", "only: refuse anything but a fast-forward.", "false: always create a merge commit."},
+		defaultValue: "true",
+	},
+	{
+		key:          "merge.conflictStyle",
+		summary:      "How conflicts are written into files.",
+		values:       []string{"merge: ours and theirs.", "diff3: ours, the common ancestor and theirs.", "zdiff3: like diff3, with common lines moved out of the conflict."},
+		defaultValue: "merge",
+	},
+	{
+		key:          "rerere.enabled",
+		summary:      "Whether Git records how you resolve conflicts and replays the resolution when the same conflict appears again.",
+		values:       []string{"true", "false"},
+		defaultValue: "false, unless a .git/rr-cache directory exists.",
+	},
+	{
+		key:          "commit.gpgSign",
+		summary:      "Whether every commit is signed.",
+		values:       []string{"true", "false"},
+		defaultValue: "false",
+	},
+	{
+		key:          "tag.gpgSign",
+		summary:      "Whether every annotated tag is signed.",
+		values:       []string{"true", "false"},
+		defaultValue: "false",
+	},
+	{
+		key:          "gpg.format",
+		summary:      "The kind of signature used for signed commits and tags.",
+		values:       []string{"openpgp", "x509", "ssh"},
+		defaultValue: "openpgp",
+	},
+	{
+		key:     "credential.helper",
+		summary: "The program that stores your passwords and tokens for HTTPS remotes.",
+		values: []string{
+			"cache: keep them in memory for a while.",
+			"store: save them in a plain-text file.",
+			"osxkeychain, manager, libsecret: use the system keychain.",
+		},
+		defaultValue: "None, so Git asks every time.",
+		check: func(value string) string {
+			if strings.HasPrefix(value, "store") {
+				return "'store' saves your credentials unencrypted in ~/.git-credentials. Prefer the system keychain or 'cache'."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "http.sslVerify",
+		summary:      "Whether Git checks the TLS certificate of HTTPS remotes.",
+		values:       []string{"true", "false"},
+		defaultValue: "true",
+		check: func(value string) string {
+			if gitConfigBool(value) == "false" {
+				return "Certificate checks are off, so anyone on the network path can impersonate the remote. Configure http.sslCAInfo with your CA instead."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "http.proxy",
+		summary:      "The proxy used for HTTP and HTTPS remotes.",
+		values:       []string{"A URL such as http://proxy.example.com:8080."},
+		defaultValue: "$https_proxy, $http_proxy or $all_proxy.",
+	},
+	{
+		key:          "safe.directory",
+		summary:      "Repositories owned by another user that Git may still operate on.",
+		values:       []string{"A path, or * for every directory."},
+		defaultValue: "None: Git refuses repositories owned by someone else.",
+		check: func(value string) string {
+			if value == "*" {
+				return "'*' turns off the ownership check everywhere, so hooks and config of repositories owned by other users can run as you."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "color.ui",
+		summary:      "Whether Git colors its output.",
+		values:       []string{"auto: only when writing to a terminal.", "always", "false"},
+		defaultValue: "auto",
+	},
+	{
+		key:          "help.autoCorrect",
+		summary:      "What Git does when you mistype a command, such as 'git comit'.",
+		values:       []string{"0: only suggest the right command.", "A number: run it after that many tenths of a second.", "immediate: run it right away.", "prompt: ask first."},
+		defaultValue: "0",
+		check: func(value string) string {
+			if value == "immediate" || value == "-1" {
+				return "Mistyped commands run immediately without a chance to cancel."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "status.showUntrackedFiles",
+		summary:      "How git status lists untracked files.",
+		values:       []string{"normal: show untracked files and directories.", "all: list every file inside untracked directories.", "no: hide untracked files."},
+		defaultValue: "normal",
+		check: func(value string) string {
+			if value == "no" {
+				return "New files are hidden from git status, so it is easy to forget to add them."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "diff.tool",
+		summary:      "The program git difftool opens.",
+		values:       []string{"A tool name such as vimdiff, meld or vscode."},
+		defaultValue: "Chosen from the tools Git finds installed.",
+	},
+	{
+		key:          "merge.tool",
+		summary:      "The program git mergetool opens to resolve conflicts.",
+		values:       []string{"A tool name such as vimdiff, meld or vscode."},
+		defaultValue: "Chosen from the tools Git finds installed.",
+	},
+	{
+		key:          "gc.auto",
+		summary:      "How many loose objects trigger an automatic git gc.",
+		values:       []string{"A number; 0 disables automatic garbage collection."},
+		defaultValue: "6700",
+		check: func(value string) string {
+			if value == "0" {
+				return "Automatic garbage collection is off, so the repository grows and slows down unless you run git gc yourself."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "gc.reflogExpireUnreachable",
+		summary:      "How long reflog entries for commits no longer on any branch are kept, which is the window for recovering them.",
+		values:       []string{"A duration such as \"30 days\", or never."},
+		defaultValue: "30 days",
+		check: func(value string) string {
+			if value == "now" || value == "0" {
+				return "Lost commits disappear from the reflog right away, so 'explain git recover' has nothing to restore."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "remote.*.url",
+		summary:      "The address Git fetches from and pushes to for this remote.",
+		values:       []string{"An https://, ssh:// or git@host:path URL, or a local path."},
+		defaultValue: "Set by git clone and git remote add.",
+		check: func(value string) string {
+			if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "git://") {
+				return "The remote is reached without encryption, so traffic can be read and changed. Use https:// or ssh."
+			}
+			if u := strings.TrimPrefix(value, "https://"); u != value && strings.Contains(strings.SplitN(u, "/", 2)[0], "@") {
+				return "The URL contains credentials, which are stored in plain text in this file."
+			}
+			return ""
+		},
+	},
+	{
+		key:          "remote.*.pushurl",
+		summary:      "A different address used only for pushing to this remote.",
+		values:       []string{"A URL."},
+		defaultValue: "remote.<name>.url",
+	},
+	{
+		key:          "remote.*.fetch",
+		summary:      "The refspec that maps the remote's branches to your remote-tracking branches.",
+		values:       []string{"A refspec such as +refs/heads/*:refs/remotes/origin/*."},
+		defaultValue: "Set by git clone and git remote add to fetch every branch.",
+	},
+	{
+		key:          "branch.*.remote",
+		summary:      "The remote this branch pulls from and pushes to.",
+		values:       []string{"A remote name, or . for the local repository."},
+		defaultValue: "Set by git push -u, git branch --set-upstream-to and git checkout of a remote branch.",
+	},
+	{
+		key:          "branch.*.merge",
+		summary:      "The remote branch this branch tracks, i.e. its upstream.",
+		values:       []string{"A ref such as refs/heads/main."},
+		defaultValue: "Set together with branch.<name>.remote.",
+	},
+	{
+		key:          "branch.*.rebase",
+		summary:      "Like pull.rebase, but for this branch only.",
+		values:       []string{"true", "false", "merges", "interactive"},
+		defaultValue: "pull.rebase",
+	},
+	{
+		key:          "alias.*",
+		summary:      "Defines a new Git command. A value starting with ! runs in the shell.",
+		values:       []string{"A Git command line without the leading git, or ! followed by a shell command."},
+		defaultValue: "No aliases.",
+	},
+	{
+		key:          "url.*.insteadOf",
+		summary:      "Rewrites remote URLs that start with the given value to start with the subsection instead.",
+		values:       []string{"A URL prefix."},
+		defaultValue: "No rewriting.",
+	},
+	{
+		key:          "include.path",
+		summary:      "Reads another configuration file at this point.",
+		values:       []string{"A path, relative to the including file if not absolute."},
+		defaultValue: "No includes.",
+	},
+	{
+		key:          "includeIf.*.path",
+		summary:      "Reads another configuration file when the condition in the subsection holds, e.g. gitdir:~/work/.",
+		values:       []string{"A path, relative to the including file if not absolute."},
+		defaultValue: "No includes.",
+	},
+}
+
+// gitConfigBool normalises the spellings Git accepts for booleans.
+func gitConfigBool(value string) string {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return "true"
+	case "false", "no", "off", "0", "":
+		return "false"
+	}
+	return value
+}
+
+// matches reports whether the normalised key name is described by k.
+func (k gitConfigKey) matches(name string) bool {
+	pattern := strings.Split(k.key, ".")
+	parts := strings.Split(name, ".")
+	if len(parts) > 3 {
+		parts = []string{parts[0], strings.Join(parts[1:len(parts)-1], "."), parts[len(parts)-1]}
+	}
+	if len(pattern) != len(parts) {
+		return false
+	}
+	for i := range pattern {
+		if pattern[i] != "*" && !strings.EqualFold(pattern[i], parts[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func findGitConfigKey(name string) (gitConfigKey, bool) {
+	for _, k := range gitConfigKeys {
+		if k.matches(name) {
+			return k, true
+		}
+	}
+	return gitConfigKey{}, false
+}
+
+func relatedGitConfigKeys(name string) []string {
+	section, _, _ := strings.Cut(name, ".")
+	var related []string
+	for _, k := range gitConfigKeys {
+		if s, _, _ := strings.Cut(k.key, "."); strings.EqualFold(s, section) {
+			related = append(related, k.key)
+		}
+	}
+	return related
+}
+
+func printGitConfigKey(k gitConfigKey, name string) {
+	fmt.Printf("%s: %s\n", k.key, k.summary)
+	fmt.Print("\n")
+	fmt.Println("Values:")
+	for _, v := range k.values {
+		fmt.Printf("  - %s\n", v)
+	}
+	fmt.Printf("Default: %s\n", k.defaultValue)
+	fmt.Print("\n")
+
+	entry, ok := currentGitConfig().get(name)
+	if !strings.Contains(name, "*") {
+		if !ok {
+			fmt.Println("Not set in your configuration, so the default applies.")
+		} else {
+			fmt.Printf("Currently: %s (%s)\n", entry.value, entry.location())
+			if k.check != nil {
+				if warning := k.check(entry.value); warning != "" {
+					fmt.Printf("!!! %s\n", warning)
+				}
+			}
+		}
+		fmt.Print("\n")
+	}
+
+	fmt.Printf("To change it for this repository:\n$ git config %s <value>\n", name)
+	fmt.Printf("For every repository:\n$ git config --global %s <value>\n", name)
+}
+
+func listGitConfigKeys() {
+	fmt.Println("Git reads its configuration from the system, global and repository files, in that order; later values win.")
+	fmt.Println("Explanations are available for the following keys (* is a remote, branch or alias name):")
+	fmt.Print("\n")
+	for _, k := range gitConfigKeys {
+		fmt.Printf("  %-28s %s\n", k.key, k.summary)
+	}
+	fmt.Print("\n")
+	fmt.Println("To audit the configuration in effect here:\n$ explain git config --audit")
+}
+
+// auditGitConfig explains every key set in the configuration files, in the
+// order Git reads them, and marks values that a later file overrides.
+func auditGitConfig(c *gitConfig) {
+	repo, _ := openGitRepo()
+	if len(c.files) == 0 {
+		fmt.Println("No Git configuration files were found; Git uses its defaults.")
+		return
+	}
+
+	effective := map[string]int{}
+	for i, e := range c.entries {
+		effective[e.key] = i
+	}
+
+	var warnings int
+	file := ""
+	for i, e := range c.entries {
+		if e.file != file {
+			file = e.file
+			if i > 0 {
+				fmt.Print("\n")
+			}
+			fmt.Printf("%s (%s)\n", file, gitConfigScope(file, repo))
+		}
+
+		k, known := findGitConfigKey(e.key)
+		description := "Not covered by explain; see 'git help config'."
+		switch {
+		case strings.HasPrefix(e.key, "alias."):
+			description = fmt.Sprintf("Alias: 'git %s' runs %s", strings.TrimPrefix(e.key, "alias."), aliasTarget(e.value))
+		case known:
+			description = k.summary
+		}
+		setting := e.key + " = " + e.value
+		if len(setting) > 36 {
+			fmt.Printf("  %s\n", setting)
+			setting = ""
+		}
+		fmt.Printf("  %-36s %s\n", setting, description)
+
+		if effective[e.key] != i && !multiValuedGitConfigKey(e.key) {
+			later := c.entries[effective[e.key]]
+			fmt.Printf("  %-36s Overridden by %s\n", "", later.location())
+			continue
+		}
+		if known && k.check != nil {
+			if warning := k.check(e.value); warning != "" {
+				fmt.Printf("  !!! %s\n", warning)
+				warnings++
+			}
+		}
+	}
+
+	fmt.Print("\n")
+	missing := false
+	for _, key := range []string{"user.name", "user.email"} {
+		if _, ok := c.get(key); !ok {
+			missing = true
+			fmt.Printf("!!! %s is not set, so Git guesses it from your system account. Set it with: git config --global %s <value>\n", key, key)
+			warnings++
+		}
+	}
+	if missing {
+		fmt.Print("\n")
+	}
+
+	switch warnings {
+	case 0:
+		fmt.Println("No risky values found.")
+	default:
+		fmt.Printf("Found %s worth reviewing.\n", plural(warnings, "value"))
+	}
+}
+
+// multiValuedGitConfigKey reports whether a key may be set several times,
+// each value adding to the others instead of replacing them.
+func multiValuedGitConfigKey(key string) bool {
+	for _, suffix := range []string{".fetch", ".push", ".pushurl", ".path", ".insteadof"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return key == "safe.directory" || key == "credential.helper"
+}
+
+// gitConfigScope names where a configuration file sits in Git's order.
+func gitConfigScope(file string, repo *gitRepo) string {
+	files := gitConfigFiles(repo)
+	switch {
+	case file == files[0]:
+		return "system"
+	case repo != nil && file == files[len(files)-1]:
+		return "repository"
+	}
+	for _, f := range files {
+		if f == file {
+			return "global"
+		}
+	}
+	return "included"
+}
+
+func aliasTarget(value string) string {
+	if strings.HasPrefix(value, "!") {
+		return "the shell command " + strings.TrimPrefix(value, "!")
+	}
+	return "git " + value
+}
@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var gitConflictCmd = &cobra.Command{
+	Use:   "conflict <file>",
+	Short: "Explains the conflict markers in a file and which side is ours or theirs",
+	Long: `This command reads a file with merge conflict markers, in the default merge
+style or the diff3 and zdiff3 styles that also show the common ancestor. It
+works out whether a merge, rebase, cherry-pick or revert is in progress, says
+what "ours" and "theirs" mean for it (a rebase swaps them), prints each
+conflict side by side and lists the commands to resolve it.
+For example:
+
+- explain git conflict src/main.go`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			fmt.Println("Could not read the file:", err)
+			return
+		}
+		hunks, err := parseConflicts(string(data))
+		if err != nil {
+			fmt.Printf("!!! %s: %v\n", args[0], err)
+			return
+		}
+		var op gitOperation
+		if repo, err := openGitRepo(); err == nil {
+			op = repo.currentOperation()
+		}
+		printConflicts(args[0], hunks, op)
+	},
+}
+
+func init() {
+	gitCmd.AddCommand(gitConflictCmd)
+}
+
+// conflictHunk is one <<<<<<< ... >>>>>>> block. base is only present in
+// the diff3 and zdiff3 styles.
+type conflictHunk struct {
+	start, end  int // 1-based lines of the opening and closing markers
+	oursLabel   string
+	ours        []string
+	hasBase     bool
+	baseLabel   string
+	base        []string
+	theirsLabel string
+	theirs      []string
+}
+
+const conflictMarkerSize = 7
+
+// conflictMarker reports whether line is a conflict marker made of c and
+// returns the label that follows it.
+func conflictMarker(line string, c byte) (string, bool) {
+	marker := strings.Repeat(string(c), conflictMarkerSize)
+	rest, ok := strings.CutPrefix(strings.TrimRight(line, "\r"), marker)
+	if !ok || (rest != "" && rest[0] != ' ') {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+func parseConflicts(text string) ([]conflictHunk, error) {
+	var hunks []conflictHunk
+	var h *conflictHunk
+	section := ""
+	for i, line := range strings.Split(text, "\n") {
+		n := i + 1
+		if label, ok := conflictMarker(line, '<'); ok {
+			if h != nil {
+				return nil, fmt.Errorf("line %d opens a conflict before the one on line %d is closed", n, h.start)
+			}
+			h = &conflictHunk{start: n, oursLabel: label}
+			section = "ours"
+			continue
+		}
+		if h == nil {
+			if _, ok := conflictMarker(line, '>'); ok {
+				return nil, fmt.Errorf("line %d closes a conflict that was never opened", n)
+			}
+			continue
+		}
+		if label, ok := conflictMarker(line, '|'); ok && section == "ours" {
+			h.hasBase, h.baseLabel = true, label
+			section = "base"
+			continue
+		}
+		if _, ok := conflictMarker(line, '='); ok && section != "theirs" {
+			section = "theirs"
+			continue
+		}
+		if label, ok := conflictMarker(line, '>'); ok && section == "theirs" {
+			h.end, h.theirsLabel = n, label
+			hunks = append(hunks, *h)
+			h = nil
+			continue
+		}
+		switch section {
+		case "ours":
+			h.ours = append(h.ours, line)
+		case "base":
+			h.base = append(h.base, line)
+		case "theirs":
+			h.theirs = append(h.theirs, line)
+		}
+	}
+	if h != nil {
+		return nil, fmt.Errorf("the conflict opened on line %d is never closed", h.start)
+	}
+	return hunks, nil
+}
+
+// gitOperation describes the merge-like operation in progress, and what
+// the two sides of a conflict stand for in it.
+type gitOperation struct {
+	name   string // merge, rebase, am, cherry-pick or revert; "" if none
+	ours   string
+	theirs string
+}
+
+// currentOperation inspects the state files Git leaves in .git while an
+// operation is stopped on conflicts.
+func (r *gitRepo) currentOperation() gitOperation {
+	head := "HEAD"
+	if branch := r.currentBranch(); branch != "" {
+		head = "HEAD, your branch " + branch
+	}
+	readFile := func(name string) string {
+		data, _ := os.ReadFile(r.path(name))
+		return strings.TrimSpace(string(data))
+	}
+	describe := func(hash string) string {
+		if c, err := r.readCommit(hash); err == nil {
+			return fmt.Sprintf("%s \"%s\"", shortHash(hash), c.subject())
+		}
+		return shortHash(hash)
+	}
+
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		if _, err := os.Stat(r.path(dir)); err != nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(r.path(dir), "applying")); err == nil {
+			return gitOperation{
+				name:   "am",
+				ours:   head + ": the branch the patches are applied to",
+				theirs: "the patch being applied",
+			}
+		}
+		branch := strings.TrimPrefix(readFile(dir+"/head-name"), "refs/heads/")
+		if branch == "" || branch == "detached HEAD" {
+			branch = "your branch"
+		}
+		onto := readFile(dir + "/onto")
+		theirs := "your commit being replayed from " + branch
+		if commit := firstNonEmpty(readFile("REBASE_HEAD"), readFile(dir+"/stopped-sha")); commit != "" {
+			theirs = describe(commit) + ", " + theirs
+		}
+		return gitOperation{
+			name:   "rebase",
+			ours:   fmt.Sprintf("HEAD, the commits being rebased onto (%s) plus those already replayed", shortHash(onto)),
+			theirs: theirs,
+		}
+	}
+
+	if merge := readFile("MERGE_HEAD"); merge != "" {
+		hashes := strings.Fields(merge)
+		theirs := describe(hashes[0])
+		msg, _, _ := strings.Cut(readFile("MERGE_MSG"), "\n")
+		if m := mergeBranchPattern.FindStringSubmatch(msg); m != nil {
+			theirs = fmt.Sprintf("%s (%s)", m[1], shortHash(hashes[0]))
+		}
+		if len(hashes) > 1 {
+			theirs += fmt.Sprintf(" and %d more (an octopus merge)", len(hashes)-1)
+		}
+		return gitOperation{name: "merge", ours: head + ": the branch being merged into", theirs: theirs + ": the branch being merged in"}
+	}
+	if pick := readFile("CHERRY_PICK_HEAD"); pick != "" {
+		return gitOperation{name: "cherry-pick", ours: head + ": where the commit is being copied to", theirs: describe(pick) + ": the commit being cherry-picked"}
+	}
+	if revert := readFile("REVERT_HEAD"); revert != "" {
+		return gitOperation{name: "revert", ours: head + ": the current state", theirs: "the parent of " + describe(revert) + ", i.e. the content before the commit being reverted"}
+	}
+	return gitOperation{}
+}
+
+func printConflicts(path string, hunks []conflictHunk, op gitOperation) {
+	if len(hunks) == 0 {
+		fmt.Printf("%s has no conflict markers.\n", path)
+		if op.name != "" {
+			fmt.Printf("If it was conflicted, mark it as resolved with: git add %s\n", shellQuote(path))
+		}
+		return
+	}
+
+	style := "merge style: only the two sides are shown"
+	if hunks[0].hasBase {
+		style = "diff3 style: the common ancestor is shown between the two sides"
+		if e, ok := currentGitConfig().get("merge.conflictstyle"); ok && strings.EqualFold(e.value, "zdiff3") {
+			style = "zdiff3 style: like diff3, with lines both sides share moved out of the conflict"
+		}
+	}
+	fmt.Printf("%s has %s (%s).\n", path, plural(len(hunks), "conflict"), style)
+	fmt.Print("\n")
+
+	if op.name == "" {
+		fmt.Println("No merge, rebase, cherry-pick or revert is in progress, so the sides are named by their labels:")
+		fmt.Printf("  ours   = %s: the version that was checked out\n", firstNonEmpty(hunks[0].oursLabel, "(no label)"))
+		fmt.Printf("  theirs = %s: the version being brought in\n", firstNonEmpty(hunks[0].theirsLabel, "(no label)"))
+		if hunks[0].oursLabel == "Updated upstream" {
+			fmt.Println("These labels come from git stash pop or git stash apply: theirs is your stashed change.")
+		}
+	} else {
+		fmt.Printf("git %s is in progress, so:\n", op.name)
+		fmt.Printf("  ours   = %s\n", op.ours)
+		fmt.Printf("  theirs = %s\n", op.theirs)
+		if op.name == "rebase" {
+			fmt.Println("!!! A rebase swaps the usual meaning: your own work is \"theirs\", and --ours picks the upstream version.")
+		}
+	}
+
+	for i, h := range hunks {
+		fmt.Print("\n")
+		fmt.Printf("Conflict %d, lines %d-%d: %s\n", i+1, h.start, h.end, describeConflict(h))
+		printSideBySide(h)
+	}
+
+	fmt.Print("\n")
+	printConflictResolution(path, hunks[0].hasBase, op)
+}
+
+// describeConflict says in a few words how the two sides differ.
+func describeConflict(h conflictHunk) string {
+	same := func(a, b []string) bool {
+		return strings.Join(a, "\n") == strings.Join(b, "\n")
+	}
+	switch {
+	case same(h.ours, h.theirs):
+		return "both sides made the same change; keep either"
+	case h.hasBase && same(h.ours, h.base):
+		return "only theirs changed this part; taking theirs is usually right"
+	case h.hasBase && same(h.theirs, h.base):
+		return "only ours changed this part; taking ours is usually right"
+	case len(h.ours) == 0:
+		return "ours removed these lines, theirs kept or changed them"
+	case len(h.theirs) == 0:
+		return "theirs removed these lines, ours kept or changed them"
+	case strings.TrimSpace(strings.Join(h.ours, "")) == strings.TrimSpace(strings.Join(h.theirs, "")):
+		return "the sides differ only in whitespace"
+	}
+	return "both sides changed the same lines"
+}
+
+const conflictColumnWidth = 38
+
+func printSideBySide(h conflictHunk) {
+	column := func(s string) string {
+		s = strings.ReplaceAll(strings.TrimRight(s, "\r"), "\t", "    ")
+		if r := []rune(s); len(r) > conflictColumnWidth {
+			s = string(r[:conflictColumnWidth-3]) + "..."
+		}
+		return s + strings.Repeat(" ", conflictColumnWidth-len([]rune(s)))
+	}
+
+	fmt.Printf("  %s | %s\n", column("ours: "+firstNonEmpty(h.oursLabel, "(no label)")), "theirs: "+firstNonEmpty(h.theirsLabel, "(no label)"))
+	fmt.Printf("  %s-+-%s\n", strings.Repeat("-", conflictColumnWidth), strings.Repeat("-", conflictColumnWidth))
+	for i := 0; i < len(h.ours) || i < len(h.theirs); i++ {
+		left, right := "", ""
+		if i < len(h.ours) {
+			left = h.ours[i]
+		}
+		if i < len(h.theirs) {
+			right = h.theirs[i]
+		}
+		fmt.Printf("  %s | %s\n", column(left), strings.TrimRight(column(right), " "))
+	}
+	if len(h.ours) == 0 && len(h.theirs) == 0 {
+		fmt.Printf("  %s | %s\n", column("(empty)"), "(empty)")
+	}
+
+	if h.hasBase {
+		fmt.Printf("  Common ancestor (%s):\n", firstNonEmpty(h.baseLabel, "base"))
+		if len(h.base) == 0 {
+			fmt.Println("    (empty: both sides added lines here)")
+		}
+		for _, line := range h.base {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
+
+func printConflictResolution(path string, hasBase bool, op gitOperation) {
+	quoted := shellQuote(path)
+	fmt.Println("To resolve it, edit the file to keep what you want, remove every marker line, then:")
+	fmt.Printf("$ git add %s\n", quoted)
+	fmt.Println("Or take one side for the whole file:")
+	fmt.Printf("$ git checkout --ours %s\n", quoted)
+	fmt.Printf("$ git checkout --theirs %s\n", quoted)
+	if !hasBase {
+		fmt.Println("To see the common ancestor too, recreate the markers in diff3 style:")
+		fmt.Printf("$ git checkout --conflict=diff3 %s\n", quoted)
+	}
+
+	if op.name == "" {
+		return
+	}
+	fmt.Print("\n")
+	fmt.Printf("When every file is resolved and added, finish the %s:\n", op.name)
+	fmt.Printf("$ git %s --continue\n", op.name)
+	fmt.Println("Or give up and go back to where you started:")
+	fmt.Printf("$ git %s --abort\n", op.name)
+
+	fmt.Print("\n")
+	switch op.name {
+	case "merge":
+		fmt.Printf("See: %s\n", gitCommandTopic("merge"))
+	case "rebase", "cherry-pick", "revert":
+		fmt.Printf("See: %s\n", gitAdvancedTopic(op.name))
+	}
+}
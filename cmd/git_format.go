@@ -0,0 +1,639 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var formatPreview int
+
+var gitFormatCmd = &cobra.Command{
+	Use:   "format <format>",
+	Short: "Labels the placeholders of a git log --format string",
+	Long: `This command parses a pretty format, as given to git log --format, --pretty or
+git show --format, and labels each placeholder, color, padding and wrapping
+directive. Built-in formats such as oneline or fuller are explained too. Inside
+a repository it previews the format against the most recent commits.
+For example:
+
+- explain git format '%h %an %ar %s %d'
+- explain git format '%C(auto)%h %<(20,trunc)%an %C(green)%ar%Creset %s'
+- explain git format fuller`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format := args[0]
+		if named, ok := namedPrettyFormats[format]; ok {
+			fmt.Printf("'%s' is a built-in format: %s\n", format, named.summary)
+			if named.equivalent == "" {
+				return
+			}
+			fmt.Printf("It is close to: --format='%s'\n", named.equivalent)
+			fmt.Print("\n")
+			format = named.equivalent
+		}
+
+		tokens, err := parsePrettyFormat(format)
+		if err != nil {
+			fmt.Printf("'%s' is not a valid format: %v\n", args[0], err)
+			return
+		}
+		printPrettyFormat(format, tokens)
+
+		if formatPreview <= 0 {
+			return
+		}
+		repo, err := openGitRepo()
+		if err != nil {
+			return
+		}
+		commits, err := repo.recentCommits(formatPreview)
+		if err != nil || len(commits) == 0 {
+			return
+		}
+		fmt.Print("\n")
+		fmt.Printf("Preview of the last %s (colors not shown):\n", plural(len(commits), "commit"))
+		for _, c := range commits {
+			for _, line := range strings.Split(renderPrettyFormat(repo, tokens, c), "\n") {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+		fmt.Print("\n")
+		fmt.Println("Try it yourself:")
+		fmt.Printf("$ git log -%d --format=%s\n", len(commits), shellQuote(args[0]))
+	},
+}
+
+func init() {
+	gitCmd.AddCommand(gitFormatCmd)
+	gitFormatCmd.Flags().IntVarP(&formatPreview, "preview", "n", 3, "Number of recent commits to preview the format with (0 to skip)")
+}
+
+type namedPrettyFormat struct {
+	summary    string
+	equivalent string
+}
+
+var namedPrettyFormats = map[string]namedPrettyFormat{
+	"oneline":   {"the full hash and the subject on one line; add --abbrev-commit for short hashes", "%H %s"},
+	"short":     {"hash, author and subject", "commit %H%nAuthor: %an <%ae>%n%n    %s%n"},
+	"medium":    {"the default: hash, author, author date and the full message", "commit %H%nAuthor: %an <%ae>%nDate:   %ad%n%n%w(0,4,4)%B"},
+	"full":      {"hash, author, committer and the full message, without dates", "commit %H%nAuthor: %an <%ae>%nCommit: %cn <%ce>%n%n%w(0,4,4)%B"},
+	"fuller":    {"hash, author and committer with both dates, and the full message", "commit %H%nAuthor:     %an <%ae>%nAuthorDate: %ad%nCommit:     %cn <%ce>%nCommitDate: %cd%n%n%w(0,4,4)%B"},
+	"reference": {"the short hash, subject and date, as used to cite a commit in a message", "%h (%s, %as)"},
+	"email":     {"a mail header and body, as git format-patch writes", ""},
+	"mboxrd":    {"like email, with From lines in the body quoted the mboxrd way", ""},
+	"raw":       {"the commit object exactly as stored, with tree and parent hashes", ""},
+}
+
+// prettyToken is one piece of a pretty format: literal text or a %
+// directive, with the +, - or space modifier that may precede it.
+type prettyToken struct {
+	raw      string
+	literal  string
+	key      string // e.g. "an", "C", "<", "w", "(trailers"
+	arg      string // the text inside parentheses
+	modifier byte
+}
+
+var (
+	personPlaceholders = "nNeElLdDrtiIsh"
+	personFields       = map[byte]string{
+		'n': "name",
+		'N': "name, respecting .mailmap",
+		'e': "email",
+		'E': "email, respecting .mailmap",
+		'l': "email local part (before the @)",
+		'L': "email local part, respecting .mailmap",
+		'd': "date, in the style --date= selects",
+		'D': "date, RFC 2822 style",
+		'r': "date, relative (e.g. 3 days ago)",
+		't': "date, UNIX timestamp",
+		'i': "date, ISO 8601-like",
+		'I': "date, strict ISO 8601",
+		's': "date, short (YYYY-MM-DD)",
+		'h': "date, human style",
+	}
+	simplePlaceholders = map[string]string{
+		"H":  "Commit hash",
+		"h":  "Abbreviated commit hash",
+		"T":  "Tree hash",
+		"t":  "Abbreviated tree hash",
+		"P":  "Parent hashes",
+		"p":  "Abbreviated parent hashes",
+		"d":  "Ref names, like git log --decorate: \" (HEAD -> main, tag: v1)\"",
+		"D":  "Ref names without the \" (\" and \")\" around them",
+		"S":  "The ref name the commit was reached by on the command line",
+		"e":  "Encoding",
+		"s":  "Subject: the first paragraph of the message, joined into one line",
+		"f":  "Subject sanitized for a file name",
+		"b":  "Body: the message after the subject",
+		"B":  "Raw body: subject and body, unwrapped",
+		"N":  "Commit notes",
+		"m":  "Left (<), right (>) or boundary (-) mark, with --left-right or --boundary",
+		"GG": "Raw verification message from GPG for a signed commit",
+		"G?": "Signature status: G good, B bad, U unknown validity, N no signature, and more",
+		"GS": "Name of the signer",
+		"GK": "Key used to sign",
+		"GF": "Fingerprint of the signing key",
+		"GP": "Fingerprint of the primary key",
+		"GT": "Trust level of the signing key",
+		"gD": "Reflog selector, e.g. refs/stash@{1}, with -g",
+		"gd": "Shortened reflog selector, e.g. stash@{1}",
+		"gn": "Reflog identity name",
+		"gN": "Reflog identity name, respecting .mailmap",
+		"ge": "Reflog identity email",
+		"gE": "Reflog identity email, respecting .mailmap",
+		"gs": "Reflog subject",
+	}
+	namedColors   = []string{"reset", "red", "green", "blue"}
+	paddingPrefix = regexp.MustCompile(`^(<|>|>>|><)(\|)?\(`)
+)
+
+func parsePrettyFormat(format string) ([]prettyToken, error) {
+	for _, prefix := range []string{"format:", "tformat:"} {
+		format = strings.TrimPrefix(format, prefix)
+	}
+	var tokens []prettyToken
+	var text strings.Builder
+	flushText := func() {
+		if text.Len() > 0 {
+			tokens = append(tokens, prettyToken{raw: text.String(), literal: text.String()})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(format); {
+		if format[i] != '%' {
+			text.WriteByte(format[i])
+			i++
+			continue
+		}
+		start := i
+		i++
+		if i >= len(format) {
+			return nil, fmt.Errorf("the format ends with a lone '%%'")
+		}
+		switch format[i] {
+		case '%':
+			text.WriteByte('%')
+			i++
+			continue
+		case 'n':
+			flushText()
+			tokens = append(tokens, prettyToken{raw: "%n", literal: "\n"})
+			i++
+			continue
+		case 'x':
+			if i+3 > len(format) {
+				return nil, fmt.Errorf("%%x needs two hex digits")
+			}
+			b, err := strconv.ParseUint(format[i+1:i+3], 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("%%x needs two hex digits, not '%s'", format[i+1:i+3])
+			}
+			flushText()
+			tokens = append(tokens, prettyToken{raw: format[start : i+3], literal: string([]byte{byte(b)})})
+			i += 3
+			continue
+		}
+		flushText()
+
+		t := prettyToken{}
+		if strings.IndexByte("+- ", format[i]) >= 0 {
+			t.modifier = format[i]
+			i++
+			if i >= len(format) {
+				return nil, fmt.Errorf("'%s' is not followed by a placeholder", format[start:])
+			}
+		}
+		rest := format[i:]
+		closeParen := func(from int) (int, error) {
+			end := strings.IndexByte(rest[from:], ')')
+			if end < 0 {
+				return 0, fmt.Errorf("'%s' is missing a closing parenthesis", format[start:])
+			}
+			return from + end, nil
+		}
+
+		switch {
+		case strings.HasPrefix(rest, "C("):
+			end, err := closeParen(2)
+			if err != nil {
+				return nil, err
+			}
+			t.key, t.arg = "C", rest[2:end]
+			i += end + 1
+		case rest[0] == 'C':
+			found := false
+			for _, color := range namedColors {
+				if strings.HasPrefix(rest[1:], color) {
+					t.key, t.arg = "C", color
+					i += 1 + len(color)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("'%s' is not a color; use %%Cred, %%Cgreen, %%Cblue, %%Creset or %%C(...)", format[start:min(len(format), i+6)])
+			}
+		case paddingPrefix.MatchString(rest):
+			m := paddingPrefix.FindString(rest)
+			end, err := closeParen(len(m))
+			if err != nil {
+				return nil, err
+			}
+			t.key, t.arg = m[:len(m)-1], rest[len(m):end]
+			i += end + 1
+		case strings.HasPrefix(rest, "w("):
+			end, err := closeParen(2)
+			if err != nil {
+				return nil, err
+			}
+			t.key, t.arg = "w", rest[2:end]
+			i += end + 1
+		case rest[0] == '(':
+			end, err := closeParen(1)
+			if err != nil {
+				return nil, err
+			}
+			name, arg, _ := strings.Cut(rest[1:end], ":")
+			t.key, t.arg = "("+name, arg
+			i += end + 1
+		case (rest[0] == 'a' || rest[0] == 'c') && len(rest) > 1 && strings.IndexByte(personPlaceholders, rest[1]) >= 0:
+			t.key = rest[:2]
+			i += 2
+		case len(rest) > 1 && simplePlaceholders[rest[:2]] != "":
+			t.key = rest[:2]
+			i += 2
+		case simplePlaceholders[rest[:1]] != "":
+			t.key = rest[:1]
+			i++
+		default:
+			return nil, fmt.Errorf("'%s' is not a known placeholder", format[start:min(len(format), i+2)])
+		}
+		t.raw = format[start:i]
+		tokens = append(tokens, t)
+	}
+	flushText()
+	return tokens, nil
+}
+
+// describe returns the label and meaning of a token.
+func (t prettyToken) describe() (string, string) {
+	var label, meaning string
+	switch {
+	case t.key == "":
+		if t.raw == "%n" {
+			return "Newline", "Starts a new line"
+		}
+		if strings.HasPrefix(t.raw, "%x") {
+			return "Byte", fmt.Sprintf("The byte 0x%s, e.g. %%x00 to separate fields for scripts", t.raw[2:])
+		}
+		return "Text", "Printed as is"
+	case t.key == "C":
+		label, meaning = "Color", describePrettyColor(t.arg)
+	case t.key == "w":
+		label, meaning = "Wrapping", describePrettyWrap(t.arg)
+	case strings.HasPrefix(t.key, "<") || strings.HasPrefix(t.key, ">"):
+		label, meaning = "Padding", describePrettyPadding(t.key, t.arg)
+	case t.key == "(trailers":
+		label, meaning = "Placeholder", "Trailers such as Signed-off-by"
+		if t.arg != "" {
+			meaning += ", with options " + t.arg
+		}
+	case t.key == "(decorate":
+		label, meaning = "Placeholder", "Ref names, with custom prefix, suffix or separator"
+	case t.key == "(describe":
+		label, meaning = "Placeholder", "A name like git describe gives: the nearest tag, commits since it and the hash"
+	case strings.HasPrefix(t.key, "("):
+		label, meaning = "Placeholder", "A named placeholder"
+	case len(t.key) == 2 && (t.key[0] == 'a' || t.key[0] == 'c'):
+		who := map[byte]string{'a': "Author", 'c': "Committer"}[t.key[0]]
+		label, meaning = "Placeholder", who+" "+personFields[t.key[1]]
+	default:
+		label, meaning = "Placeholder", simplePlaceholders[t.key]
+	}
+	switch t.modifier {
+	case '+':
+		meaning += "; '+' adds a newline before it unless it is empty"
+	case '-':
+		meaning += "; '-' removes the newlines before it if it is empty"
+	case ' ':
+		meaning += "; ' ' adds a space before it unless it is empty"
+	}
+	return label, meaning
+}
+
+func describePrettyColor(spec string) string {
+	switch spec {
+	case "reset":
+		return "Back to the default color"
+	case "auto":
+		return "Colors the following placeholders the way git log does, when color is enabled"
+	case "red", "green", "blue":
+		return "Switches to " + spec
+	}
+	words := strings.Fields(strings.ReplaceAll(spec, ",", " "))
+	if len(words) > 0 && (words[0] == "always" || words[0] == "auto") {
+		force := map[string]string{"always": "even when the output is not a terminal", "auto": "only when color is enabled"}[words[0]]
+		return fmt.Sprintf("Switches to %s, %s", strings.Join(words[1:], " "), force)
+	}
+	return fmt.Sprintf("Switches to %s, when color is enabled", spec)
+}
+
+func describePrettyWrap(arg string) string {
+	parts := strings.Split(arg, ",")
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+	if parts[0] == "" || parts[0] == "0" {
+		if parts[1] == "0" && parts[2] == "0" {
+			return "Turns wrapping off"
+		}
+		return fmt.Sprintf("Indents the following text: the first line by %s, the others by %s, without wrapping", parts[1], parts[2])
+	}
+	return fmt.Sprintf("Wraps the following text at %s columns, indenting the first line by %s and the others by %s", parts[0], parts[1], parts[2])
+}
+
+func describePrettyPadding(key, arg string) string {
+	width, mode, _ := strings.Cut(arg, ",")
+	column := strings.HasSuffix(key, "|")
+	key = strings.TrimSuffix(key, "|")
+
+	size := "to " + width + " characters"
+	if column {
+		size = "up to column " + width
+	}
+	var s string
+	switch key {
+	case "<":
+		s = "Pads the next placeholder on the right " + size + ", so it is left-aligned"
+	case ">":
+		s = "Pads the next placeholder on the left " + size + ", so it is right-aligned"
+	case ">>":
+		s = "Like %>(), but may take space from the padding of earlier placeholders"
+	case "><":
+		s = "Centers the next placeholder, padding both sides " + size
+	}
+	switch mode {
+	case "trunc":
+		s += "; longer values are cut at the end with '..'"
+	case "ltrunc":
+		s += "; longer values are cut at the start with '..'"
+	case "mtrunc":
+		s += "; longer values are cut in the middle with '..'"
+	}
+	return s
+}
+
+func printPrettyFormat(format string, tokens []prettyToken) {
+	fmt.Printf("Format: %s\n", format)
+	switch {
+	case strings.HasPrefix(format, "format:"):
+		fmt.Println("'format:' puts the format between commits, so the last one has no trailing newline.")
+	case strings.HasPrefix(format, "tformat:"):
+		fmt.Println("'tformat:' ends every commit, including the last, with a newline. A format with a % and no prefix works the same way.")
+	}
+	fmt.Print("\n")
+
+	var v flagValue
+	colored, reset := false, false
+	for _, t := range tokens {
+		label, meaning := t.describe()
+		value := t.raw
+		if t.key == "" && !strings.HasPrefix(t.raw, "%") {
+			value = strconv.Quote(t.raw)
+		}
+		v.add(value, label, meaning)
+		if t.key == "C" {
+			if t.arg == "reset" {
+				reset = true
+			} else if t.arg != "auto" {
+				colored = true
+			}
+		}
+	}
+	if colored && !reset {
+		v.warn("The format sets a color but never resets it with %%Creset, so the color runs into the next line.")
+	}
+	printFlagValue(v, "  ")
+}
+
+// recentCommits returns up to n commits reachable from HEAD, newest first by
+// commit date, as git log lists them.
+func (r *gitRepo) recentCommits(n int) ([]gitCommit, error) {
+	head, ok := r.readRef("HEAD")
+	if !ok {
+		return nil, fmt.Errorf("HEAD has no commits yet")
+	}
+	first, err := r.readCommit(head)
+	if err != nil {
+		return nil, err
+	}
+	pending := []gitCommit{first}
+	seen := map[string]bool{head: true}
+	var commits []gitCommit
+	for len(pending) > 0 && len(commits) < n {
+		sort.SliceStable(pending, func(i, j int) bool {
+			return pending[i].committer.when.After(pending[j].committer.when)
+		})
+		c := pending[0]
+		pending = pending[1:]
+		commits = append(commits, c)
+		for _, p := range c.parents {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			if parent, err := r.readCommit(p); err == nil {
+				pending = append(pending, parent)
+			}
+		}
+	}
+	return commits, nil
+}
+
+// renderPrettyFormat expands tokens for c. Colors are left out, %w()
+// indents without wrapping, and placeholders that need more than the commit
+// itself (signatures, reflogs) are kept as written.
+func renderPrettyFormat(repo *gitRepo, tokens []prettyToken, c gitCommit) string {
+	var out strings.Builder
+	var pad *prettyToken
+	first, rest := 0, 0 // indentation set by %w()
+	for i := range tokens {
+		t := tokens[i]
+		switch {
+		case t.key == "":
+			out.WriteString(t.literal)
+			continue
+		case t.key == "C":
+			continue
+		case t.key == "w":
+			indent := strings.Split(t.arg+",0,0", ",")
+			first, rest = atoi(indent[1]), atoi(indent[2])
+			continue
+		case strings.HasPrefix(t.key, "<") || strings.HasPrefix(t.key, ">"):
+			pad = &tokens[i]
+			continue
+		}
+
+		value := expandPlaceholder(repo, t, c)
+		if first > 0 || rest > 0 {
+			lines := strings.Split(value, "\n")
+			for j, line := range lines {
+				indent := rest
+				if j == 0 {
+					indent = first
+				}
+				if line != "" {
+					lines[j] = strings.Repeat(" ", indent) + line
+				}
+			}
+			value = strings.Join(lines, "\n")
+		}
+		if pad != nil {
+			line := out.String()
+			if nl := strings.LastIndexByte(line, '\n'); nl >= 0 {
+				line = line[nl+1:]
+			}
+			value = applyPrettyPadding(pad.key, pad.arg, value, len([]rune(line)))
+			pad = nil
+		}
+		switch {
+		case t.modifier == '+' && value != "":
+			value = "\n" + value
+		case t.modifier == ' ' && value != "":
+			value = " " + value
+		case t.modifier == '-' && value == "":
+			trimmed := strings.TrimRight(out.String(), "\n")
+			out.Reset()
+			out.WriteString(trimmed)
+		}
+		out.WriteString(value)
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
+
+func expandPlaceholder(repo *gitRepo, t prettyToken, c gitCommit) string {
+	if len(t.key) == 2 && (t.key[0] == 'a' || t.key[0] == 'c') {
+		sig := c.author
+		if t.key[0] == 'c' {
+			sig = c.committer
+		}
+		return formatSignatureField(sig, t.key[1])
+	}
+
+	_, body, _ := strings.Cut(strings.TrimSpace(c.message), "\n\n")
+	short := func(hashes []string) string {
+		out := make([]string, len(hashes))
+		for i, h := range hashes {
+			out[i] = shortHash(h)
+		}
+		return strings.Join(out, " ")
+	}
+	switch t.key {
+	case "H":
+		return c.hash
+	case "h":
+		return shortHash(c.hash)
+	case "T":
+		return c.tree
+	case "t":
+		return shortHash(c.tree)
+	case "P":
+		return strings.Join(c.parents, " ")
+	case "p":
+		return short(c.parents)
+	case "d":
+		if refs := repo.refsPointingAt(c.hash); len(refs) > 0 {
+			return " (" + strings.Join(refs, ", ") + ")"
+		}
+		return ""
+	case "D":
+		return strings.Join(repo.refsPointingAt(c.hash), ", ")
+	case "s":
+		return c.subject()
+	case "f":
+		return sanitizeSubject(c.subject())
+	case "b":
+		return body
+	case "B":
+		return c.message
+	case "N", "e", "m", "S":
+		return ""
+	}
+	return t.raw
+}
+
+func formatSignatureField(sig gitSignature, field byte) string {
+	local, _, _ := strings.Cut(sig.email, "@")
+	switch field {
+	case 'n', 'N':
+		return sig.name
+	case 'e', 'E':
+		return sig.email
+	case 'l', 'L':
+		return local
+	case 'd':
+		return sig.when.Format("Mon Jan 2 15:04:05 2006 -0700")
+	case 'D':
+		return sig.when.Format("Mon, 2 Jan 2006 15:04:05 -0700")
+	case 'r', 'h':
+		return timeAgo(sig.when)
+	case 't':
+		return strconv.FormatInt(sig.when.Unix(), 10)
+	case 'i':
+		return sig.when.Format("2006-01-02 15:04:05 -0700")
+	case 'I':
+		return sig.when.Format(time.RFC3339)
+	case 's':
+		return sig.when.Format("2006-01-02")
+	}
+	return ""
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._]+`)
+
+// sanitizeSubject turns a subject into the file name part %f prints.
+func sanitizeSubject(s string) string {
+	return strings.Trim(unsafeFileChars.ReplaceAllString(s, "-"), "-.")
+}
+
+// applyPrettyPadding pads or truncates value for a %<(), %>() or %><()
+// directive; column is where value starts on the current line.
+func applyPrettyPadding(key, arg, value string, column int) string {
+	widthText, mode, _ := strings.Cut(arg, ",")
+	width := atoi(widthText)
+	if strings.HasSuffix(key, "|") {
+		width -= column
+		key = strings.TrimSuffix(key, "|")
+	}
+	runes := []rune(value)
+	if len(runes) > width && width > 2 {
+		switch mode {
+		case "trunc":
+			return string(runes[:width-2]) + ".."
+		case "ltrunc":
+			return ".." + string(runes[len(runes)-width+2:])
+		case "mtrunc":
+			left := (width - 2) / 2
+			return string(runes[:left]) + ".." + string(runes[len(runes)-(width-2-left):])
+		}
+	}
+	gap := width - len(runes)
+	if gap <= 0 {
+		return value
+	}
+	switch key {
+	case ">", ">>":
+		return strings.Repeat(" ", gap) + value
+	case "><":
+		return strings.Repeat(" ", gap/2) + value + strings.Repeat(" ", gap-gap/2)
+	}
+	return value + strings.Repeat(" ", gap)
+}
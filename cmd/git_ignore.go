@@ -0,0 +1,419 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var ignoreCheck string
+
+var gitIgnoreCmd = &cobra.Command{
+	Use:   "ignore [pattern]",
+	Short: "Explains .gitignore patterns and why a file is ignored",
+	Long: `This command explains what a .gitignore pattern matches, including leading and
+trailing slashes, ** and negation, and lists files in the current repository it
+matches. With --check it reads the repository's ignore files and reports which
+rule in which file decides whether a path is ignored.
+For example:
+
+- explain git ignore '/build/'
+- explain git ignore '**/*.log'
+- explain git ignore --check dist/app.js`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if ignoreCheck != "" {
+			repo, err := openGitRepo()
+			if err != nil {
+				fmt.Println("Could not check the path:", err)
+				return
+			}
+			checkGitIgnore(repo, ignoreCheck)
+			return
+		}
+		if len(args) == 0 {
+			fmt.Println("Please provide a pattern to explain, e.g. explain git ignore '*.log', or a path with --check.")
+			return
+		}
+
+		rule, ok := parseIgnoreRule(args[0], "", "", 0)
+		if !ok {
+			fmt.Printf("'%s' is a blank line or a comment, so Git ignores it.\n", args[0])
+			return
+		}
+		explainIgnoreRule(rule)
+		if repo, err := openGitRepo(); err == nil {
+			printIgnoreRuleMatches(repo, rule)
+		}
+	},
+}
+
+func init() {
+	gitCmd.AddCommand(gitIgnoreCmd)
+
+	gitIgnoreCmd.Flags().StringVar(&ignoreCheck, "check", "", "Report which ignore rule applies to this path")
+}
+
+// ignoreRule is one pattern line of a .gitignore file. base is the directory
+// holding the file, relative to the top of the working tree, and rules only
+// apply below it.
+type ignoreRule struct {
+	text     string
+	file     string
+	line     int
+	base     string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+	glob     string
+	re       *regexp.Regexp
+}
+
+// parseIgnoreRule parses a line of a .gitignore file. Blank lines and
+// comments are not rules.
+func parseIgnoreRule(text, file, base string, line int) (ignoreRule, bool) {
+	r := ignoreRule{text: text, file: file, base: base, line: line}
+	p := trimIgnoreSpaces(text)
+	if p == "" || strings.HasPrefix(p, "#") {
+		return r, false
+	}
+	if strings.HasPrefix(p, "!") {
+		r.negate = true
+		p = p[1:]
+	} else if strings.HasPrefix(p, `\!`) || strings.HasPrefix(p, `\#`) {
+		p = p[1:]
+	}
+	if strings.HasSuffix(p, "/") {
+		r.dirOnly = true
+		p = strings.TrimRight(p, "/")
+	}
+	if strings.Contains(p, "/") {
+		r.anchored = true
+		p = strings.TrimPrefix(p, "/")
+	}
+	if p == "" {
+		return r, false
+	}
+	r.glob = p
+	r.re = globRegexp(p)
+	return r, true
+}
+
+// trimIgnoreSpaces removes trailing spaces unless they are escaped with a
+// backslash.
+func trimIgnoreSpaces(s string) string {
+	for strings.HasSuffix(s, " ") && !strings.HasSuffix(s, `\ `) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// globRegexp translates a wildmatch glob into a regular expression: "*" and
+// "?" never match "/", while "**" spans directories when it makes up a
+// whole path component.
+func globRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**") && (i == 0 || glob[i-1] == '/'):
+			rest := glob[i+2:]
+			switch {
+			case rest == "":
+				b.WriteString(".*")
+				i++
+			case rest[0] == '/':
+				b.WriteString("(?:.*/)?")
+				i += 2
+			default:
+				b.WriteString("[^/]*")
+				i++
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			// A "]" right after "[" or "[!" is part of the set.
+			start := i + 1
+			if start < len(glob) && glob[start] == '!' {
+				start++
+			}
+			if start < len(glob) && glob[start] == ']' {
+				start++
+			}
+			end := strings.IndexByte(glob[start:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : start+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			class = strings.ReplaceAll(class, `\`, `\\`)
+			class = strings.ReplaceAll(class, "[", `\[`)
+			class = strings.ReplaceAll(class, "]", `\]`)
+			b.WriteString("[" + class + "]")
+			i = start + end
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return regexp.MustCompile("^" + regexp.QuoteMeta(glob) + "$")
+	}
+	return re
+}
+
+// matches reports whether the rule matches rel, a slash-separated path
+// relative to the top of the working tree.
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		var ok bool
+		if rel, ok = strings.CutPrefix(rel, r.base+"/"); !ok {
+			return false
+		}
+	}
+	if r.anchored {
+		return r.re.MatchString(rel)
+	}
+	return r.re.MatchString(path.Base(rel))
+}
+
+func (r ignoreRule) location() string {
+	return fmt.Sprintf("%s:%d", r.file, r.line)
+}
+
+func explainIgnoreRule(r ignoreRule) {
+	fmt.Printf("Pattern: %s\n", r.text)
+	fmt.Print("\n")
+
+	if r.negate {
+		fmt.Println("- '!' negates the pattern: paths it matches are included again, even if an earlier pattern ignored them.")
+		fmt.Println("  It cannot re-include a file whose parent directory is ignored; Git never looks inside ignored directories.")
+	}
+	if strings.HasPrefix(trimIgnoreSpaces(r.text), `\`) {
+		fmt.Println("- The leading backslash escapes '#' or '!', so the pattern matches names starting with that character.")
+	}
+	switch {
+	case strings.HasPrefix(strings.TrimPrefix(r.text, "!"), "/"):
+		fmt.Println("- The leading '/' anchors the pattern to the directory of the .gitignore file, so it only matches there, not in subdirectories.")
+	case r.anchored && strings.HasPrefix(r.glob, "**/"):
+		fmt.Println("- The leading '**/' matches in every directory, at any depth.")
+	case r.anchored:
+		fmt.Println("- The pattern contains a '/', so it is relative to the directory of the .gitignore file, as if it started with '/'.")
+	default:
+		fmt.Println("- The pattern has no '/', so it matches a file or directory with this name at any depth.")
+	}
+	if r.dirOnly {
+		fmt.Println("- The trailing '/' makes it match directories only, together with everything inside them, but not files of that name.")
+	} else {
+		fmt.Println("- It matches files and directories alike; a matching directory is ignored with everything inside it.")
+	}
+
+	if strings.HasSuffix(r.glob, "/**") {
+		fmt.Println("- The trailing '/**' matches everything inside the directory, but not the directory itself.")
+	}
+	if strings.Contains(r.glob, "/**/") {
+		fmt.Println("- '/**/' matches zero or more directories, so a/**/b matches a/b, a/x/b and a/x/y/b.")
+	}
+	glob := strings.ReplaceAll(r.glob, "**", "")
+	if strings.Contains(glob, "*") {
+		fmt.Println("- '*' matches any characters within a single path component; it does not cross '/'.")
+	}
+	if strings.Contains(glob, "?") {
+		fmt.Println("- '?' matches exactly one character other than '/'.")
+	}
+	if strings.Contains(glob, "[") {
+		fmt.Println("- '[...]' matches one character from the set; '[!...]' matches one character not in it.")
+	}
+	if strings.HasSuffix(r.text, `\ `) {
+		fmt.Println("- The escaped trailing space is part of the name. Unescaped trailing spaces are removed.")
+	}
+	fmt.Print("\n")
+	fmt.Println("Files Git already tracks are not affected by .gitignore. To stop tracking one:\n$ git rm --cached <file>")
+}
+
+// printIgnoreRuleMatches lists paths in the working tree that the rule
+// matches when written in the top-level .gitignore.
+func printIgnoreRuleMatches(repo *gitRepo, r ignoreRule) {
+	var matches []string
+	more := 0
+	filepath.WalkDir(repo.workTree, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == repo.workTree {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(repo.workTree, p)
+		rel = filepath.ToSlash(rel)
+		if !r.matches(rel, d.IsDir()) {
+			return nil
+		}
+		if len(matches) < 10 {
+			if d.IsDir() {
+				rel += "/"
+			}
+			matches = append(matches, rel)
+		} else {
+			more++
+		}
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	fmt.Print("\n")
+	if len(matches) == 0 {
+		fmt.Println("In the top-level .gitignore of this repository it would match nothing.")
+		return
+	}
+	fmt.Println("In the top-level .gitignore of this repository it would match:")
+	for _, m := range matches {
+		fmt.Printf("  %s\n", m)
+	}
+	if more > 0 {
+		fmt.Printf("  ... and %d more\n", more)
+	}
+}
+
+// ignoreRulesFor returns every rule that can apply to rel, lowest priority
+// first: core.excludesFile, .git/info/exclude, then each .gitignore from
+// the top of the working tree down to the directory holding rel.
+func ignoreRulesFor(repo *gitRepo, rel string) []ignoreRule {
+	var rules []ignoreRule
+
+	excludes := ""
+	if e, ok := currentGitConfig().get("core.excludesFile"); ok {
+		excludes = expandGitConfigPath(e.value, repo.workTree)
+	} else if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		excludes = filepath.Join(xdg, "git", "ignore")
+	} else if home, err := os.UserHomeDir(); err == nil {
+		excludes = filepath.Join(home, ".config", "git", "ignore")
+	}
+	if excludes != "" {
+		rules = append(rules, readIgnoreFile(excludes, "")...)
+	}
+	rules = append(rules, readIgnoreFile(repo.path("info/exclude"), "")...)
+
+	dir := ""
+	rules = append(rules, readIgnoreFile(filepath.Join(repo.workTree, ".gitignore"), "")...)
+	for _, part := range strings.Split(path.Dir(rel), "/") {
+		if part == "." || part == "" {
+			break
+		}
+		dir = path.Join(dir, part)
+		rules = append(rules, readIgnoreFile(filepath.Join(repo.workTree, filepath.FromSlash(dir), ".gitignore"), dir)...)
+	}
+	return rules
+}
+
+func readIgnoreFile(file, base string) []ignoreRule {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if r, ok := parseIgnoreRule(scanner.Text(), file, base, n); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// ignoreDecision finds the last rule matching rel, which is the one Git
+// applies.
+func ignoreDecision(rules []ignoreRule, rel string, isDir bool) (ignoreRule, bool) {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].matches(rel, isDir) {
+			return rules[i], true
+		}
+	}
+	return ignoreRule{}, false
+}
+
+func checkGitIgnore(repo *gitRepo, target string) {
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		fmt.Println("Could not check the path:", err)
+		return
+	}
+	rel, err := filepath.Rel(repo.workTree, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		fmt.Printf("'%s' is outside the repository at %s.\n", target, repo.workTree)
+		return
+	}
+	rel = filepath.ToSlash(rel)
+	isDir := strings.HasSuffix(target, "/")
+	if info, err := os.Stat(abs); err == nil {
+		isDir = info.IsDir()
+	}
+	rules := ignoreRulesFor(repo, rel)
+
+	// Git stops at the first ignored parent directory, so rules for the
+	// path itself never get a say.
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		parent := strings.Join(parts[:i], "/")
+		if r, ok := ignoreDecision(rules, parent, true); ok && !r.negate {
+			fmt.Printf("%s is ignored because its directory %s/ is ignored.\n", rel, parent)
+			fmt.Printf("  Rule: %-24s %s\n", r.text, r.location())
+			fmt.Print("\n")
+			fmt.Println("Git does not look inside ignored directories, so no rule, not even a '!' pattern, can include files in it.")
+			fmt.Printf("To include it, ignore the directory's contents instead of the directory, e.g. '%s/*', then add '!%s'.\n", parent, rel)
+			return
+		}
+	}
+
+	var matching []ignoreRule
+	for _, r := range rules {
+		if r.matches(rel, isDir) {
+			matching = append(matching, r)
+		}
+	}
+	if len(matching) == 0 {
+		fmt.Printf("%s is not ignored: no rule in the %d ignore rules that apply to it matches.\n", rel, len(rules))
+		return
+	}
+
+	decision := matching[len(matching)-1]
+	if decision.negate {
+		fmt.Printf("%s is not ignored: it is included again by a '!' rule.\n", rel)
+	} else {
+		fmt.Printf("%s is ignored.\n", rel)
+	}
+	fmt.Printf("  Rule: %-24s %s\n", decision.text, decision.location())
+	if len(matching) > 1 {
+		fmt.Print("\n")
+		fmt.Println("Matching rules, in the order Git reads them. The last one wins:")
+		for _, r := range matching {
+			fmt.Printf("  %-30s %s\n", r.text, r.location())
+		}
+	}
+	if !decision.negate {
+		fmt.Print("\n")
+		fmt.Printf("If %s is already tracked, the rule has no effect. To stop tracking it:\n$ git rm --cached %s\n", rel, rel)
+	}
+}
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var objectsDepth int
+
+var gitObjectsCmd = &cobra.Command{
+	Use:   "objects [rev]",
+	Short: "Walks the objects behind a commit and explains how Git stores them",
+	Long: `This command reads the object database of the repository in the current
+directory and prints the objects a revision is made of: an annotated tag if
+there is one, the commit, its root tree, and the subtrees and blobs below it,
+with their hashes and sizes. It then explains the loose objects, packfiles and
+refs it finds under .git. The revision defaults to HEAD.
+For example:
+
+- explain git objects
+- explain git objects v1.0 --depth 1
+- explain git objects HEAD~2`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rev := "HEAD"
+		if len(args) == 1 {
+			rev = args[0]
+		}
+		repo, err := openGitRepo()
+		if err != nil {
+			fmt.Println("Could not read the objects:", err)
+			return
+		}
+		hash, err := repo.resolveRev(rev)
+		if err != nil {
+			fmt.Println("Could not read the objects:", err)
+			return
+		}
+		printObjectKinds()
+		fmt.Print("\n")
+		if err := repo.printObjectGraph(rev, hash, objectsDepth); err != nil {
+			fmt.Println("!!! Could not read the objects:", err)
+			return
+		}
+		fmt.Print("\n")
+		repo.printObjectStorage()
+	},
+}
+
+func init() {
+	gitCmd.AddCommand(gitObjectsCmd)
+	gitObjectsCmd.Flags().IntVarP(&objectsDepth, "depth", "d", 2, "Number of directory levels to expand below the root tree")
+}
+
+func printObjectKinds() {
+	fmt.Println("Git stores everything as objects, each named by the SHA-1 hash of its content:")
+	fmt.Println("  blob    The contents of one file, without its name or permissions")
+	fmt.Println("  tree    A directory: names and modes, each pointing to a blob or another tree")
+	fmt.Println("  commit  A snapshot: one root tree, the parent commits, author, committer and message")
+	fmt.Println("  tag     An annotated tag: a name, tagger and message pointing to another object")
+	fmt.Println("Identical content is stored once, so unchanged files and directories are shared between commits.")
+}
+
+// printObjectGraph prints the ref chain that rev follows and the objects
+// below hash, expanding trees depth levels below the root.
+func (r *gitRepo) printObjectGraph(rev, hash string, depth int) error {
+	fmt.Println(r.refChain(rev, hash))
+	if location := r.objectLocation(hash); location != "" {
+		fmt.Printf("%s is stored in %s\n", shortHash(hash), location)
+	}
+	fmt.Print("\n")
+
+	obj, err := r.readObject(hash)
+	if err != nil {
+		return err
+	}
+	prefix := ""
+	for obj.kind == "tag" {
+		t := parseGitTag(obj)
+		r.printObjectLine(obj, "", fmt.Sprintf("'%s', tagged by %s", t.name, t.tagger.name))
+		if obj, err = r.readObject(t.object); err != nil {
+			return err
+		}
+		fmt.Print("└─ ")
+		prefix = "   "
+	}
+
+	switch obj.kind {
+	case "commit":
+		c, err := parseGitCommit(obj)
+		if err != nil {
+			return err
+		}
+		r.printObjectLine(obj, "", fmt.Sprintf("\"%s\" by %s, %s", c.subject(), c.author.name, timeAgo(c.committer.when)))
+		for _, parent := range c.parents {
+			fmt.Printf("%s├─ parent %s  (the commit before it; walk it with: explain git objects %s)\n", prefix, shortHash(parent), shortHash(parent))
+		}
+		tree, err := r.readObject(c.tree)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s└─ ", prefix)
+		return r.printTree(tree, "root directory", prefix+"   ", depth)
+	case "tree":
+		return r.printTree(obj, "directory", prefix, depth)
+	default:
+		r.printObjectLine(obj, "", describeBytes(int64(len(obj.data))))
+	}
+	return nil
+}
+
+// printTree prints a tree object and, while depth allows, its entries.
+func (r *gitRepo) printTree(tree gitObject, name, prefix string, depth int) error {
+	entries, err := parseGitTree(tree)
+	if err != nil {
+		return err
+	}
+	r.printObjectLine(tree, name, treeEntries(len(entries)))
+	if depth < 0 {
+		return nil
+	}
+
+	for i, e := range entries {
+		connector, childPrefix := "├─ ", "│  "
+		if i == len(entries)-1 {
+			connector, childPrefix = "└─ ", "   "
+		}
+		fmt.Print(prefix + connector)
+		switch {
+		case e.isSubmodule():
+			fmt.Printf("commit %s  %s  (a submodule: this commit lives in another repository)\n", shortHash(e.hash), e.name)
+		case e.isDir() && depth == 0:
+			fmt.Printf("tree   %s  %s/  (not expanded; use --depth to see more)\n", shortHash(e.hash), e.name)
+		case e.isDir():
+			sub, err := r.readObject(e.hash)
+			if err != nil {
+				fmt.Printf("tree   %s  %s/  !!! %v\n", shortHash(e.hash), e.name, err)
+				continue
+			}
+			if err := r.printTree(sub, e.name+"/", prefix+childPrefix, depth-1); err != nil {
+				return err
+			}
+		default:
+			blob, err := r.readObject(e.hash)
+			if err != nil {
+				fmt.Printf("blob   %s  %s  !!! %v\n", shortHash(e.hash), e.name, err)
+				continue
+			}
+			detail := describeBytes(int64(len(blob.data)))
+			switch e.mode {
+			case "100755":
+				detail += ", executable"
+			case "120000":
+				detail = "a symlink to " + string(blob.data)
+			}
+			r.printObjectLine(blob, e.name, detail)
+		}
+	}
+	return nil
+}
+
+func treeEntries(n int) string {
+	if n == 1 {
+		return "1 entry"
+	}
+	return fmt.Sprintf("%d entries", n)
+}
+
+func (r *gitRepo) printObjectLine(obj gitObject, name, detail string) {
+	if name != "" {
+		name += "  "
+	}
+	fmt.Printf("%-6s %s  %s(%s)\n", obj.kind, shortHash(obj.hash), name, detail)
+}
+
+// refChain describes how rev leads to hash, e.g.
+// "HEAD -> refs/heads/main (.git/refs/heads/main) -> 1a2b3c4".
+func (r *gitRepo) refChain(rev, hash string) string {
+	parts := []string{rev}
+	name := ""
+	switch {
+	case rev == "HEAD" || rev == "@":
+		if branch := r.currentBranch(); branch != "" {
+			name = "refs/heads/" + branch
+		}
+	case strings.ContainsAny(rev, "~^@:"):
+	default:
+		for _, candidate := range []string{"refs/" + rev, "refs/tags/" + rev, "refs/heads/" + rev, "refs/remotes/" + rev} {
+			if _, ok := r.readRef(candidate); ok {
+				name = candidate
+				break
+			}
+		}
+	}
+	if name != "" {
+		where := "packed-refs"
+		if _, err := os.Stat(r.path(name)); err == nil {
+			where = r.relPath(r.path(name))
+		}
+		parts = append(parts, fmt.Sprintf("%s (stored in %s)", name, where))
+	}
+	return strings.Join(append(parts, shortHash(hash)), " -> ")
+}
+
+var looseObjectDir = regexp.MustCompile(`^[0-9a-f]{2}$`)
+
+// printObjectStorage explains the loose objects, packfiles and refs found
+// under .git.
+func (r *gitRepo) printObjectStorage() {
+	fmt.Println("On disk:")
+
+	var looseCount int
+	var looseSize int64
+	objects := r.path("objects")
+	dirs, _ := os.ReadDir(objects)
+	for _, d := range dirs {
+		if !d.IsDir() || !looseObjectDir.MatchString(d.Name()) {
+			continue
+		}
+		files, _ := os.ReadDir(filepath.Join(objects, d.Name()))
+		for _, f := range files {
+			if info, err := f.Info(); err == nil {
+				looseCount++
+				looseSize += info.Size()
+			}
+		}
+	}
+	fmt.Printf("  .git/objects/??/      %s, %s\n", plural(looseCount, "loose object"), describeBytes(looseSize))
+	fmt.Println("      Each file is one zlib-compressed object; the first two hex digits of its hash name the directory.")
+	fmt.Println("      New commits, trees and blobs are written this way.")
+
+	var packCount, packedObjects int
+	var packSize int64
+	for _, p := range r.packIndexes() {
+		if filepath.Dir(filepath.Dir(p.pack)) != objects {
+			continue
+		}
+		packCount++
+		packedObjects += p.count()
+		if info, err := os.Stat(p.pack); err == nil {
+			packSize += info.Size()
+		}
+	}
+	fmt.Printf("  .git/objects/pack/    %s holding %s, %s\n", plural(packCount, "packfile"), plural(packedObjects, "object"), describeBytes(packSize))
+	fmt.Println("      git gc, git fetch and git clone store objects together in packfiles, many of them as deltas")
+	fmt.Println("      against similar objects. Each .pack has an .idx file to find objects by hash.")
+	if alternates := r.objectDirs()[1:]; len(alternates) > 0 {
+		fmt.Printf("      Objects are also borrowed from %s (objects/info/alternates).\n", strings.Join(alternates, ", "))
+	}
+
+	looseRefs := 0
+	filepath.WalkDir(r.path("refs"), func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			looseRefs++
+		}
+		return nil
+	})
+	fmt.Printf("  .git/refs/            %s\n", plural(looseRefs, "loose ref"))
+	fmt.Println("      One small file per branch, tag or remote-tracking branch, holding the hash it points to.")
+	if packed := len(r.packedRefs()); packed > 0 {
+		fmt.Printf("  .git/packed-refs      %s\n", plural(packed, "packed ref"))
+		fmt.Println("      Refs written into a single file by git pack-refs or git clone. A loose ref with the same name wins.")
+	}
+	if data, err := os.ReadFile(r.path("HEAD")); err == nil {
+		head := strings.TrimSpace(string(data))
+		if strings.HasPrefix(head, "ref: ") {
+			fmt.Printf("  .git/HEAD             %s\n", head)
+			fmt.Println("      A symbolic ref naming the branch that is checked out; committing moves that branch.")
+		} else {
+			fmt.Printf("  .git/HEAD             %s\n", shortHash(head))
+			fmt.Println("      A commit hash instead of a branch: HEAD is detached.")
+		}
+	}
+
+	fmt.Print("\n")
+	fmt.Println("Inspect any object yourself with:")
+	fmt.Println("$ git cat-file -p <hash>")
+	fmt.Println("$ git count-objects -vH")
+}
@@ -0,0 +1,531 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitObject is an object read from the object database, with its delta
+// chains already resolved.
+type gitObject struct {
+	hash string
+	kind string // commit, tree, blob or tag
+	data []byte
+}
+
+// packIndex is a version 2 .idx file loaded into memory. names holds the
+// sorted 20-byte object names, offsets their positions in the .pack file.
+type packIndex struct {
+	pack    string
+	names   []byte
+	offsets []uint64
+}
+
+func (p *packIndex) count() int {
+	return len(p.offsets)
+}
+
+func (p *packIndex) name(i int) string {
+	return hex.EncodeToString(p.names[i*20 : i*20+20])
+}
+
+// find returns the index of the first name that starts with prefix.
+func (p *packIndex) find(prefix string) int {
+	return sort.Search(p.count(), func(i int) bool {
+		return p.name(i)[:len(prefix)] >= prefix
+	})
+}
+
+var errObjectNotFound = errors.New("object not found")
+
+// objectDirs returns the repository's object directory followed by any
+// alternates it borrows objects from.
+func (r *gitRepo) objectDirs() []string {
+	dirs := []string{r.path("objects")}
+	data, err := os.ReadFile(filepath.Join(dirs[0], "info", "alternates"))
+	if err != nil {
+		return dirs
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(dirs[0], line)
+		}
+		dirs = append(dirs, line)
+	}
+	return dirs
+}
+
+// packIndexes loads every pack index once per run.
+func (r *gitRepo) packIndexes() []*packIndex {
+	if r.packs != nil {
+		return r.packs
+	}
+	r.packs = []*packIndex{}
+	for _, dir := range r.objectDirs() {
+		idxFiles, _ := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+		for _, idx := range idxFiles {
+			if p, err := readPackIndex(idx); err == nil {
+				r.packs = append(r.packs, p)
+			}
+		}
+	}
+	return r.packs
+}
+
+func readPackIndex(path string) (*packIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 8+256*4 || !bytes.Equal(data[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(data[4:8]) != 2 {
+		return nil, fmt.Errorf("%s is not a version 2 pack index", path)
+	}
+	n := int(binary.BigEndian.Uint32(data[8+255*4 : 8+256*4]))
+	namesAt := 8 + 256*4
+	offsetsAt := namesAt + n*20 + n*4
+	largeAt := offsetsAt + n*4
+	if len(data) < largeAt {
+		return nil, fmt.Errorf("%s is truncated", path)
+	}
+
+	p := &packIndex{
+		pack:    strings.TrimSuffix(path, ".idx") + ".pack",
+		names:   data[namesAt : namesAt+n*20],
+		offsets: make([]uint64, n),
+	}
+	for i := 0; i < n; i++ {
+		off := binary.BigEndian.Uint32(data[offsetsAt+i*4:])
+		if off&0x80000000 == 0 {
+			p.offsets[i] = uint64(off)
+			continue
+		}
+		// Offsets above 2GiB live in a separate table of 8-byte entries.
+		at := largeAt + int(off&0x7fffffff)*8
+		if at+8 > len(data) {
+			return nil, fmt.Errorf("%s is truncated", path)
+		}
+		p.offsets[i] = binary.BigEndian.Uint64(data[at:])
+	}
+	return p, nil
+}
+
+// findObjects returns the full names of the objects starting with prefix,
+// looking at loose objects and packs.
+func (r *gitRepo) findObjects(prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	seen := map[string]bool{}
+	var found []string
+	add := func(hash string) {
+		if !seen[hash] {
+			seen[hash] = true
+			found = append(found, hash)
+		}
+	}
+
+	if len(prefix) >= 2 {
+		for _, dir := range r.objectDirs() {
+			entries, _ := os.ReadDir(filepath.Join(dir, prefix[:2]))
+			for _, e := range entries {
+				if hash := prefix[:2] + e.Name(); strings.HasPrefix(hash, prefix) && len(hash) == 40 {
+					add(hash)
+				}
+			}
+		}
+	}
+	for _, p := range r.packIndexes() {
+		for i := p.find(prefix); i < p.count() && strings.HasPrefix(p.name(i), prefix); i++ {
+			add(p.name(i))
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// readObject reads an object by its full name.
+func (r *gitRepo) readObject(hash string) (gitObject, error) {
+	for _, dir := range r.objectDirs() {
+		obj, err := readLooseObject(filepath.Join(dir, hash[:2], hash[2:]))
+		if err == nil {
+			obj.hash = hash
+			return obj, nil
+		}
+		if !os.IsNotExist(err) {
+			return gitObject{}, err
+		}
+	}
+	for _, p := range r.packIndexes() {
+		i := p.find(hash)
+		if i < p.count() && p.name(i) == hash {
+			obj, err := r.readPackedObject(p.pack, p.offsets[i])
+			obj.hash = hash
+			return obj, err
+		}
+	}
+	return gitObject{}, fmt.Errorf("%w: %s", errObjectNotFound, hash)
+}
+
+// objectLocation reports where an object is stored: the path of its loose
+// file or of the packfile that holds it, relative to the repository.
+func (r *gitRepo) objectLocation(hash string) string {
+	for _, dir := range r.objectDirs() {
+		path := filepath.Join(dir, hash[:2], hash[2:])
+		if _, err := os.Stat(path); err == nil {
+			return r.relPath(path)
+		}
+	}
+	for _, p := range r.packIndexes() {
+		if i := p.find(hash); i < p.count() && p.name(i) == hash {
+			return r.relPath(p.pack)
+		}
+	}
+	return ""
+}
+
+// readLooseObject inflates a file under .git/objects, which holds a
+// "<type> <size>\0" header followed by the content.
+func readLooseObject(path string) (gitObject, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return gitObject{}, err
+	}
+	defer f.Close()
+	z, err := zlib.NewReader(f)
+	if err != nil {
+		return gitObject{}, err
+	}
+	defer z.Close()
+	data, err := io.ReadAll(z)
+	if err != nil {
+		return gitObject{}, err
+	}
+	header, content, ok := bytes.Cut(data, []byte{0})
+	kind, _, _ := strings.Cut(string(header), " ")
+	if !ok {
+		return gitObject{}, fmt.Errorf("%s is not a Git object", path)
+	}
+	return gitObject{kind: kind, data: content}, nil
+}
+
+var packObjectKinds = map[byte]string{1: "commit", 2: "tree", 3: "blob", 4: "tag"}
+
+const (
+	packOfsDelta = 6
+	packRefDelta = 7
+)
+
+// readPackedObject reads the object at offset in a pack file, applying
+// deltas against their base objects.
+func (r *gitRepo) readPackedObject(pack string, offset uint64) (gitObject, error) {
+	f, err := os.Open(pack)
+	if err != nil {
+		return gitObject{}, err
+	}
+	defer f.Close()
+	return r.readPackEntry(f, offset, 0)
+}
+
+func (r *gitRepo) readPackEntry(f *os.File, offset uint64, depth int) (gitObject, error) {
+	if depth > 64 {
+		return gitObject{}, fmt.Errorf("delta chain in %s is too deep", f.Name())
+	}
+	in := bufio.NewReader(io.NewSectionReader(f, int64(offset), 1<<62))
+
+	c, err := in.ReadByte()
+	if err != nil {
+		return gitObject{}, err
+	}
+	kind := (c >> 4) & 7
+	for c&0x80 != 0 {
+		if c, err = in.ReadByte(); err != nil {
+			return gitObject{}, err
+		}
+	}
+
+	var base gitObject
+	switch kind {
+	case packOfsDelta:
+		c, err := in.ReadByte()
+		if err != nil {
+			return gitObject{}, err
+		}
+		distance := uint64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = in.ReadByte(); err != nil {
+				return gitObject{}, err
+			}
+			distance = (distance+1)<<7 | uint64(c&0x7f)
+		}
+		if base, err = r.readPackEntry(f, offset-distance, depth+1); err != nil {
+			return gitObject{}, err
+		}
+	case packRefDelta:
+		name := make([]byte, 20)
+		if _, err := io.ReadFull(in, name); err != nil {
+			return gitObject{}, err
+		}
+		if base, err = r.readObject(hex.EncodeToString(name)); err != nil {
+			return gitObject{}, err
+		}
+	}
+
+	z, err := zlib.NewReader(in)
+	if err != nil {
+		return gitObject{}, err
+	}
+	defer z.Close()
+	data, err := io.ReadAll(z)
+	if err != nil {
+		return gitObject{}, err
+	}
+
+	if kind == packOfsDelta || kind == packRefDelta {
+		data, err = applyDelta(base.data, data)
+		return gitObject{kind: base.kind, data: data}, err
+	}
+	name, ok := packObjectKinds[kind]
+	if !ok {
+		return gitObject{}, fmt.Errorf("unknown object type %d in %s", kind, f.Name())
+	}
+	return gitObject{kind: name, data: data}, nil
+}
+
+// applyDelta rebuilds an object from its base and a delta made of "copy
+// from base" and "insert new bytes" instructions.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	errCorrupt := errors.New("corrupt delta")
+	varint := func() (uint64, bool) {
+		var n uint64
+		for shift := 0; len(delta) > 0; shift += 7 {
+			c := delta[0]
+			delta = delta[1:]
+			n |= uint64(c&0x7f) << shift
+			if c&0x80 == 0 {
+				return n, true
+			}
+		}
+		return 0, false
+	}
+	if size, ok := varint(); !ok || size != uint64(len(base)) {
+		return nil, errCorrupt
+	}
+	size, ok := varint()
+	if !ok {
+		return nil, errCorrupt
+	}
+
+	out := make([]byte, 0, size)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		switch {
+		case op&0x80 != 0:
+			var offset, n uint64
+			for i := 0; i < 4; i++ {
+				if op&(1<<i) != 0 {
+					if len(delta) == 0 {
+						return nil, errCorrupt
+					}
+					offset |= uint64(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			for i := 0; i < 3; i++ {
+				if op&(0x10<<i) != 0 {
+					if len(delta) == 0 {
+						return nil, errCorrupt
+					}
+					n |= uint64(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			if n == 0 {
+				n = 0x10000
+			}
+			if offset+n > uint64(len(base)) {
+				return nil, errCorrupt
+			}
+			out = append(out, base[offset:offset+n]...)
+		case op != 0:
+			if int(op) > len(delta) {
+				return nil, errCorrupt
+			}
+			out = append(out, delta[:op]...)
+			delta = delta[op:]
+		default:
+			return nil, errCorrupt
+		}
+	}
+	if uint64(len(out)) != size {
+		return nil, errCorrupt
+	}
+	return out, nil
+}
+
+// gitSignature is the author, committer or tagger line of an object.
+type gitSignature struct {
+	name  string
+	email string
+	when  time.Time
+}
+
+// parseGitSignature parses "Name <email> <unix time> <+hhmm>".
+func parseGitSignature(s string) gitSignature {
+	var sig gitSignature
+	open, end := strings.Index(s, "<"), strings.LastIndex(s, ">")
+	if open < 0 || end < open {
+		sig.name = s
+		return sig
+	}
+	sig.name = strings.TrimSpace(s[:open])
+	sig.email = s[open+1 : end]
+	fields := strings.Fields(s[end+1:])
+	if len(fields) == 2 {
+		seconds, _ := strconv.ParseInt(fields[0], 10, 64)
+		zone, _ := strconv.Atoi(fields[1])
+		offset := (zone/100*60 + zone%100) * 60
+		sig.when = time.Unix(seconds, 0).In(time.FixedZone(fields[1], offset))
+	}
+	return sig
+}
+
+func (s gitSignature) String() string {
+	return fmt.Sprintf("%s <%s>", s.name, s.email)
+}
+
+// gitCommit is a parsed commit object.
+type gitCommit struct {
+	hash      string
+	tree      string
+	parents   []string
+	author    gitSignature
+	committer gitSignature
+	signed    bool
+	message   string
+}
+
+func parseGitCommit(obj gitObject) (gitCommit, error) {
+	if obj.kind != "commit" {
+		return gitCommit{}, fmt.Errorf("%s is a %s, not a commit", shortHash(obj.hash), obj.kind)
+	}
+	c := gitCommit{hash: obj.hash}
+	header, message, _ := strings.Cut(string(obj.data), "\n\n")
+	c.message = message
+	for _, line := range strings.Split(header, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			c.tree = value
+		case "parent":
+			c.parents = append(c.parents, value)
+		case "author":
+			c.author = parseGitSignature(value)
+		case "committer":
+			c.committer = parseGitSignature(value)
+		case "gpgsig", "gpgsig-sha256":
+			c.signed = true
+		}
+	}
+	return c, nil
+}
+
+// subject returns the first line of the commit message.
+func (c gitCommit) subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(c.message), "\n")
+	return subject
+}
+
+// gitTag is a parsed annotated tag object.
+type gitTag struct {
+	object  string
+	kind    string
+	name    string
+	tagger  gitSignature
+	message string
+}
+
+func parseGitTag(obj gitObject) gitTag {
+	var t gitTag
+	header, message, _ := strings.Cut(string(obj.data), "\n\n")
+	t.message = message
+	for _, line := range strings.Split(header, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "object":
+			t.object = value
+		case "type":
+			t.kind = value
+		case "tag":
+			t.name = value
+		case "tagger":
+			t.tagger = parseGitSignature(value)
+		}
+	}
+	return t
+}
+
+// gitTreeEntry is one entry of a tree object.
+type gitTreeEntry struct {
+	mode string
+	name string
+	hash string
+}
+
+func (e gitTreeEntry) isDir() bool {
+	return e.mode == "40000"
+}
+
+func (e gitTreeEntry) isSubmodule() bool {
+	return e.mode == "160000"
+}
+
+// parseGitTree parses the binary "<mode> <name>\0<20-byte hash>" entries of
+// a tree object.
+func parseGitTree(obj gitObject) ([]gitTreeEntry, error) {
+	if obj.kind != "tree" {
+		return nil, fmt.Errorf("%s is a %s, not a tree", shortHash(obj.hash), obj.kind)
+	}
+	var entries []gitTreeEntry
+	data := obj.data
+	for len(data) > 0 {
+		header, rest, ok := bytes.Cut(data, []byte{0})
+		if !ok || len(rest) < 20 {
+			return nil, fmt.Errorf("tree %s is corrupt", shortHash(obj.hash))
+		}
+		mode, name, _ := strings.Cut(string(header), " ")
+		entries = append(entries, gitTreeEntry{mode: mode, name: name, hash: hex.EncodeToString(rest[:20])})
+		data = rest[20:]
+	}
+	return entries, nil
+}
+
+func (r *gitRepo) readCommit(hash string) (gitCommit, error) {
+	obj, err := r.readObject(hash)
+	if err != nil {
+		return gitCommit{}, err
+	}
+	return parseGitCommit(obj)
+}
+
+func (r *gitRepo) readTree(hash string) ([]gitTreeEntry, error) {
+	obj, err := r.readObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	return parseGitTree(obj)
+}
@@ -0,0 +1,483 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var gitOutputCmd = &cobra.Command{
+	Use:   "output [file]",
+	Short: "Annotates the output of git status --porcelain, git diff or git log --graph",
+	Long: `This command reads the output of a Git command and explains it line by line.
+It understands git status --porcelain (v1 and v2) and git status --short,
+git diff and git show patches (file headers, modes, renames and hunk headers),
+and git log --graph. The output can be piped in, pasted, or read from a file.
+For example:
+
+- git status --porcelain=v2 --branch | explain git output
+- git diff | explain git output
+- git log --graph --oneline -20 | explain git output
+- explain git output saved-diff.txt`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var data []byte
+		var err error
+		switch {
+		case len(args) == 1 && args[0] != "-":
+			data, err = os.ReadFile(args[0])
+		case stdinIsPiped():
+			data, err = io.ReadAll(os.Stdin)
+		default:
+			fmt.Println("Paste the output, then press Ctrl-D on a new line:")
+			data, err = io.ReadAll(os.Stdin)
+			fmt.Print("\n")
+		}
+		if err != nil {
+			fmt.Println("Could not read the output:", err)
+			return
+		}
+
+		lines := strings.Split(strings.TrimRight(ansiEscape.ReplaceAllString(string(data), ""), "\n"), "\n")
+		switch kind := detectGitOutput(lines); kind {
+		case "status-v2":
+			fmt.Println("This is git status --porcelain=v2 output.")
+			fmt.Print("\n")
+			annotateStatusV2(lines)
+		case "status-v1":
+			fmt.Println("This is git status --porcelain (v1) or git status --short output.")
+			fmt.Println("Each line starts with two columns: X is the staging area (index), Y the working tree.")
+			fmt.Print("\n")
+			annotateStatusV1(lines)
+		case "diff":
+			fmt.Println("This is a patch, as printed by git diff, git show or git log -p.")
+			fmt.Print("\n")
+			annotateDiff(lines)
+		case "log":
+			fmt.Println("This is git log --graph output. Each column of '|' is a line of history; '*' marks a commit on it.")
+			fmt.Print("\n")
+			annotateLogGraph(lines)
+		default:
+			fmt.Println(`This does not look like output explain understands. It reads:
+  git status --porcelain, --porcelain=v2 or --short
+  git diff, git show and git log -p
+  git log --graph`)
+		}
+	},
+}
+
+func init() {
+	gitCmd.AddCommand(gitOutputCmd)
+}
+
+var (
+	ansiEscape      = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	statusV1Line    = regexp.MustCompile(`^[ MTADRCU?!][ MTADRCU?!] \S`)
+	statusV2Line    = regexp.MustCompile(`^(?:[12u] [.MTADRCU]{2} [NS][.CMU]{3} |[?!] |# branch\.)`)
+	graphPrefix     = regexp.MustCompile(`^[*|/\\_. -]*`)
+	graphCommitLine = regexp.MustCompile(`^[|/\\ ]*\*[|/\\ ]*(?:[0-9a-f]{7,40}\b|commit [0-9a-f]{7,40})`)
+	hunkHeader      = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+	combinedHunk    = regexp.MustCompile(`^@@@+ `)
+	graphCommit     = regexp.MustCompile(`^(?:commit )?([0-9a-f]{7,40})(?: \(([^)]*)\))?`)
+)
+
+// detectGitOutput guesses which command printed lines.
+func detectGitOutput(lines []string) string {
+	counts := map[string]int{}
+	for _, line := range lines {
+		switch {
+		case statusV2Line.MatchString(line):
+			counts["status-v2"]++
+		case strings.HasPrefix(line, "diff --git ") || strings.HasPrefix(line, "+++ ") || hunkHeader.MatchString(line):
+			counts["diff"]++
+		case graphCommitLine.MatchString(line):
+			counts["log"]++
+		case statusV1Line.MatchString(line) || strings.HasPrefix(line, "## "):
+			counts["status-v1"]++
+		}
+	}
+	for _, kind := range []string{"log", "diff", "status-v2", "status-v1"} {
+		if counts[kind] > 0 {
+			return kind
+		}
+	}
+	return ""
+}
+
+// annotate prints a line of output followed by what it means.
+func annotate(line string, notes ...string) {
+	fmt.Println(line)
+	for _, note := range notes {
+		fmt.Printf("  ↳ %s\n", note)
+	}
+}
+
+var statusCodes = map[byte]string{
+	'M': "modified",
+	'T': "changed type (e.g. file to symlink)",
+	'A': "added",
+	'D': "deleted",
+	'R': "renamed",
+	'C': "copied",
+}
+
+var unmergedCodes = map[string]string{
+	"DD": "both sides deleted the file",
+	"AU": "added by us, changed by them",
+	"UD": "changed by us, deleted by them",
+	"UA": "added by them, changed by us",
+	"DU": "deleted by us, changed by them",
+	"AA": "both sides added a file at this path",
+	"UU": "both sides modified the file",
+}
+
+// describeXY explains a two-letter status code; v2 uses '.' where v1 uses
+// a space.
+func describeXY(xy string) string {
+	if len(xy) != 2 {
+		return ""
+	}
+	if meaning, ok := unmergedCodes[xy]; ok {
+		return "Unmerged, " + meaning + ". Resolve the conflict, then git add it."
+	}
+	switch xy {
+	case "??":
+		return "Untracked: Git does not know this file yet. git add starts tracking it."
+	case "!!":
+		return "Ignored by a .gitignore rule."
+	}
+	var parts []string
+	if meaning, ok := statusCodes[xy[0]]; ok {
+		parts = append(parts, "Staged: "+meaning+", will be in the next commit")
+	}
+	if meaning, ok := statusCodes[xy[1]]; ok {
+		parts = append(parts, "Not staged: "+meaning+" in the working tree; git add to include it")
+	}
+	return strings.Join(parts, ". ") + "."
+}
+
+func annotateStatusV1(lines []string) {
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "## "):
+			annotate(line, describeBranchHeader(strings.TrimPrefix(line, "## ")))
+		case statusV1Line.MatchString(line):
+			notes := []string{describeXY(line[:2])}
+			if old, new, ok := strings.Cut(line[3:], " -> "); ok {
+				notes = append(notes, fmt.Sprintf("Was %s, now %s.", old, new))
+			}
+			annotate(line, notes...)
+		default:
+			annotate(line)
+		}
+	}
+}
+
+var branchHeader = regexp.MustCompile(`^(\S+?)(?:\.\.\.(\S+))?(?: \[(.*)\])?$`)
+
+// describeBranchHeader explains the "## main...origin/main [ahead 1]" line
+// that --branch adds.
+func describeBranchHeader(h string) string {
+	if branch, ok := strings.CutPrefix(h, "No commits yet on "); ok {
+		return fmt.Sprintf("On branch %s, which has no commits yet.", branch)
+	}
+	if strings.HasPrefix(h, "HEAD (no branch)") {
+		return "HEAD is detached: no branch is checked out."
+	}
+	m := branchHeader.FindStringSubmatch(h)
+	if m == nil {
+		return ""
+	}
+	s := "On branch " + m[1]
+	if m[2] != "" {
+		s += ", tracking " + m[2]
+	}
+	switch {
+	case m[3] == "gone":
+		s += ". The upstream branch no longer exists on the remote"
+	case m[3] != "":
+		s += ". " + strings.ToUpper(m[3][:1]) + m[3][1:] + " commits compared with it"
+	case m[2] != "":
+		s += ". Up to date with it"
+	}
+	return s + "."
+}
+
+func annotateStatusV2(lines []string) {
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		switch {
+		case strings.HasPrefix(line, "# branch.oid "):
+			annotate(line, "The commit HEAD points to; (initial) means there are no commits yet.")
+		case strings.HasPrefix(line, "# branch.head "):
+			annotate(line, "The current branch; (detached) means no branch is checked out.")
+		case strings.HasPrefix(line, "# branch.upstream "):
+			annotate(line, "The upstream branch this one tracks.")
+		case strings.HasPrefix(line, "# branch.ab ") && len(fields) == 4:
+			annotate(line, fmt.Sprintf("%s ahead of and %s behind the upstream.", plural(atoi(fields[2]), "commit"), plural(-atoi(fields[3]), "commit")))
+		case strings.HasPrefix(line, "# stash "):
+			annotate(line, "The number of stash entries.")
+		case strings.HasPrefix(line, "1 ") && len(fields) >= 9:
+			fields = strings.SplitN(line, " ", 9)
+			annotate(line, describeXY(strings.ReplaceAll(fields[1], ".", " ")),
+				describeSubmodule(fields[2]),
+				describeModes(fields[3], fields[4], fields[5]),
+				fmt.Sprintf("Object names in HEAD and in the index: %s, %s.", shortHash(fields[6]), shortHash(fields[7])))
+		case strings.HasPrefix(line, "2 ") && len(fields) >= 10:
+			fields = strings.SplitN(line, " ", 10)
+			path, orig, _ := strings.Cut(fields[9], "\t")
+			score := fields[8]
+			verb := map[byte]string{'R': "Renamed", 'C': "Copied"}[score[0]]
+			annotate(line, describeXY(strings.ReplaceAll(fields[1], ".", " ")),
+				fmt.Sprintf("%s from %s to %s, %s%% similar.", firstNonEmpty(verb, "Moved"), orig, path, score[1:]))
+		case strings.HasPrefix(line, "u ") && len(fields) >= 11:
+			annotate(line, describeXY(fields[1]),
+				fmt.Sprintf("Versions: common ancestor %s, ours %s, theirs %s.", shortHash(fields[7]), shortHash(fields[8]), shortHash(fields[9])))
+		case strings.HasPrefix(line, "? "):
+			annotate(line, describeXY("??"))
+		case strings.HasPrefix(line, "! "):
+			annotate(line, describeXY("!!"))
+		default:
+			annotate(line)
+		}
+	}
+}
+
+func describeSubmodule(sub string) string {
+	if sub == "N..." || len(sub) != 4 {
+		return "N...: not a submodule."
+	}
+	var parts []string
+	if sub[1] == 'C' {
+		parts = append(parts, "its commit changed")
+	}
+	if sub[2] == 'M' {
+		parts = append(parts, "it has tracked changes")
+	}
+	if sub[3] == 'U' {
+		parts = append(parts, "it has untracked files")
+	}
+	if len(parts) == 0 {
+		return "A submodule with no changes."
+	}
+	return "A submodule: " + strings.Join(parts, ", ") + "."
+}
+
+func describeModes(head, index, worktree string) string {
+	if head == index && index == worktree {
+		return fmt.Sprintf("Mode %s (%s) in HEAD, index and working tree.", head, describeFileMode(head))
+	}
+	return fmt.Sprintf("Modes in HEAD, index and working tree: %s, %s, %s.", head, index, worktree)
+}
+
+func describeFileMode(mode string) string {
+	switch strings.TrimLeft(mode, "0") {
+	case "100644":
+		return "a regular file"
+	case "100755":
+		return "an executable file"
+	case "120000":
+		return "a symlink"
+	case "160000":
+		return "a submodule"
+	case "40000":
+		return "a directory"
+	case "":
+		return "does not exist"
+	}
+	return "an unusual mode"
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func annotateDiff(lines []string) {
+	run, kind := 0, byte(0)
+	flush := func() {
+		if run == 0 {
+			return
+		}
+		switch kind {
+		case '+':
+			fmt.Printf("  ↳ %s added\n", plural(run, "line"))
+		case '-':
+			fmt.Printf("  ↳ %s removed\n", plural(run, "line"))
+		case ' ':
+			fmt.Printf("  ↳ %s of unchanged context\n", plural(run, "line"))
+		}
+		run = 0
+	}
+
+	inHunk := false
+	for _, line := range lines {
+		if inHunk && line != "" && strings.ContainsRune("+- ", rune(line[0])) && !strings.HasPrefix(line, "--- ") && !strings.HasPrefix(line, "+++ ") {
+			if line[0] != kind {
+				flush()
+				kind = line[0]
+			}
+			run++
+			fmt.Println(line)
+			continue
+		}
+		flush()
+		note := describeDiffLine(line)
+		if strings.HasPrefix(line, "@@") {
+			inHunk = true
+		} else if strings.HasPrefix(line, "diff ") {
+			inHunk = false
+		}
+		if note == "" {
+			annotate(line)
+		} else {
+			annotate(line, note)
+		}
+	}
+	flush()
+}
+
+func describeDiffLine(line string) string {
+	word := func(prefix string) string {
+		return strings.TrimSpace(strings.TrimPrefix(line, prefix))
+	}
+	switch {
+	case strings.HasPrefix(line, "diff --git "):
+		return "The changes to one file start here. a/ is the version before, b/ the version after."
+	case strings.HasPrefix(line, "diff --cc ") || strings.HasPrefix(line, "diff --combined "):
+		return "A combined diff of a merge: each line has one column per parent."
+	case strings.HasPrefix(line, "new file mode "):
+		return "The file was created, as " + describeFileMode(word("new file mode ")) + "."
+	case strings.HasPrefix(line, "deleted file mode "):
+		return "The file was deleted."
+	case strings.HasPrefix(line, "old mode "):
+		return "The mode before: " + describeFileMode(word("old mode ")) + "."
+	case strings.HasPrefix(line, "new mode "):
+		return "The mode after: " + describeFileMode(word("new mode ")) + ". Only permissions or the file type changed."
+	case strings.HasPrefix(line, "similarity index "):
+		return fmt.Sprintf("%s of the file is unchanged, so Git treats it as a rename or copy.", word("similarity index "))
+	case strings.HasPrefix(line, "dissimilarity index "):
+		return fmt.Sprintf("%s of the file changed, so Git shows it as rewritten.", word("dissimilarity index "))
+	case strings.HasPrefix(line, "rename from "), strings.HasPrefix(line, "copy from "):
+		return "The old path."
+	case strings.HasPrefix(line, "rename to "), strings.HasPrefix(line, "copy to "):
+		return "The new path."
+	case strings.HasPrefix(line, "index "):
+		hashes, mode, _ := strings.Cut(word("index "), " ")
+		note := "Blob hashes before and after the change"
+		if mode != "" {
+			note += "; the mode is " + mode + " (" + describeFileMode(mode) + ") on both sides"
+		}
+		if strings.HasPrefix(hashes, "0000000..") {
+			note += ". All zeros: the file did not exist"
+		}
+		return note + "."
+	case strings.HasPrefix(line, "Binary files "):
+		return "Git does not show line changes for binary files."
+	case strings.HasPrefix(line, "--- /dev/null"):
+		return "There is no old version: the file is new."
+	case strings.HasPrefix(line, "+++ /dev/null"):
+		return "There is no new version: the file was deleted."
+	case strings.HasPrefix(line, "--- "):
+		return "Lines starting with '-' come from the old version."
+	case strings.HasPrefix(line, "+++ "):
+		return "Lines starting with '+' come from the new version."
+	case strings.HasPrefix(line, `\ No newline at end of file`):
+		return "The line above is the last in its file and has no newline after it."
+	case combinedHunk.MatchString(line):
+		return "A hunk of a combined diff: one -range per parent, then the merge result's +range."
+	}
+	if m := hunkHeader.FindStringSubmatch(line); m != nil {
+		note := fmt.Sprintf("A hunk: %s of the old file become %s of the new file.", describeHunkRange(m[1], m[2]), describeHunkRange(m[3], m[4]))
+		if m[5] != "" {
+			note += fmt.Sprintf(" '%s' is the nearest function or section above, for orientation.", strings.TrimSpace(m[5]))
+		}
+		return note
+	}
+	return ""
+}
+
+// describeHunkRange explains a start,count pair from a hunk header. The
+// count defaults to 1, and a count of 0 means an empty range after start.
+func describeHunkRange(start, count string) string {
+	n := 1
+	if count != "" {
+		n = atoi(count)
+	}
+	first := atoi(start)
+	switch n {
+	case 0:
+		if first == 0 {
+			return "nothing (the file was empty or missing)"
+		}
+		return fmt.Sprintf("no lines (after line %d)", first)
+	case 1:
+		return fmt.Sprintf("line %d", first)
+	}
+	return fmt.Sprintf("lines %d-%d", first, first+n-1)
+}
+
+func annotateLogGraph(lines []string) {
+	for _, line := range lines {
+		prefix := graphPrefix.FindString(line)
+		rest := strings.TrimPrefix(line, prefix)
+		switch {
+		case graphCommitLine.MatchString(line):
+			column := strings.Index(prefix, "*")/2 + 1
+			m := graphCommit.FindStringSubmatch(rest)
+			notes := []string{fmt.Sprintf("Commit %s, on line of history %d.", shortHash(m[1]), column)}
+			if m[2] != "" {
+				notes = append(notes, describeDecorations(m[2])...)
+			}
+			annotate(line, notes...)
+		case strings.Contains(prefix, `\`):
+			annotate(line, "A line of history splits off to the right: the merge above has another parent there.")
+		case strings.Contains(prefix, "/"):
+			annotate(line, "A line of history joins from the right: its commits branched off from the commit below.")
+		case strings.HasPrefix(rest, "Merge: "):
+			annotate(line, "The parents of this merge commit: the first is the branch merged into.")
+		case strings.HasPrefix(rest, "Author: "):
+			annotate(line, "Who wrote the change.")
+		case strings.HasPrefix(rest, "Date: "):
+			annotate(line, "When it was written (the author date).")
+		default:
+			annotate(line)
+		}
+	}
+}
+
+// describeDecorations explains the "(HEAD -> main, origin/main, tag: v1)"
+// list git log --decorate prints.
+func describeDecorations(list string) []string {
+	var notes []string
+	for _, d := range strings.Split(list, ", ") {
+		switch {
+		case strings.HasPrefix(d, "HEAD -> "):
+			notes = append(notes, fmt.Sprintf("HEAD -> %s: you are on branch %s, and it points here.", d[8:], d[8:]))
+		case d == "HEAD":
+			notes = append(notes, "HEAD: checked out here, detached from any branch.")
+		case strings.HasPrefix(d, "tag: "):
+			notes = append(notes, fmt.Sprintf("%s: a tag on this commit.", d))
+		case isRemoteBranch(d):
+			notes = append(notes, fmt.Sprintf("%s: where the branch was on the remote at the last fetch.", d))
+		default:
+			notes = append(notes, fmt.Sprintf("%s: a local branch pointing here.", d))
+		}
+	}
+	return notes
+}
+
+// isRemoteBranch reports whether a decoration such as origin/main names a
+// remote-tracking branch rather than a local branch with a slash.
+func isRemoteBranch(name string) bool {
+	remote, _, ok := strings.Cut(name, "/")
+	if !ok {
+		return false
+	}
+	_, configured := currentGitConfig().get("remote." + remote + ".url")
+	return configured
+}
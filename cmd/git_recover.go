@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	recoverBranch string
+	recoverLimit  int
+)
+
+var gitRecoverCmd = &cobra.Command{
+	Use:   "recover [entry]",
+	Short: "Lists recent reflog entries and shows how to restore one",
+	Long: `This command reads the reflog of the repository in the current directory and
+describes every recent movement of HEAD (or of a branch) in plain words.
+Pass the number of an entry to get the exact commands that restore it.
+For example:
+
+- explain git recover
+- explain git recover 3
+- explain git recover --branch main`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if recoverLimit < 1 {
+			fmt.Printf("--limit must be at least 1, got %d.\n", recoverLimit)
+			return
+		}
+		repo, err := openGitRepo()
+		if err != nil {
+			fmt.Println("Could not read the reflog:", err)
+			return
+		}
+
+		ref, logPath := "HEAD", repo.path("logs/HEAD")
+		if recoverBranch != "" {
+			ref, logPath = recoverBranch, repo.path("logs/refs/heads/"+recoverBranch)
+		}
+		entries, err := readReflog(logPath)
+		if err != nil {
+			fmt.Printf("Could not read the reflog of %s: %v\n", ref, err)
+			return
+		}
+		if len(entries) == 0 {
+			fmt.Printf("The reflog of %s is empty.\n", ref)
+			return
+		}
+
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 0 || n >= len(entries) {
+				fmt.Printf("'%s' is not a reflog entry. Pick a number between 0 and %d.\n", args[0], len(entries)-1)
+				return
+			}
+			printRecoverCommands(ref, n, entries[n])
+			return
+		}
+
+		fmt.Printf("- Recent movements of %s, newest first:\n", ref)
+		fmt.Print("\n")
+		for i, e := range entries {
+			if i == recoverLimit {
+				fmt.Printf("... %d older entries. Use --limit to see more.\n", len(entries)-i)
+				break
+			}
+			fmt.Printf("%3d  %-10s %s  %-14s %s\n", i, fmt.Sprintf("%s@{%d}", ref, i), shortHash(e.newHash), timeAgo(e.when), describeReflogEntry(e))
+		}
+		fmt.Print("\n")
+		fmt.Println("To restore one of these states:")
+		fmt.Println("$ explain git recover <number>")
+		fmt.Print("\n")
+		fmt.Printf("See: %s\n", gitAdvancedTopic("reflog"))
+	},
+}
+
+func init() {
+	gitCmd.AddCommand(gitRecoverCmd)
+
+	gitRecoverCmd.Flags().StringVarP(&recoverBranch, "branch", "b", "", "Read the reflog of a branch instead of HEAD")
+	gitRecoverCmd.Flags().IntVarP(&recoverLimit, "limit", "n", 15, "Maximum number of entries to list")
+}
+
+// reflogEntry is one line of a file under .git/logs.
+type reflogEntry struct {
+	oldHash string
+	newHash string
+	who     string
+	when    time.Time
+	message string
+}
+
+// readReflog parses a reflog file and returns its entries newest first, in
+// the order used by the ref@{n} syntax.
+func readReflog(path string) ([]reflogEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []reflogEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if e, ok := parseReflogLine(scanner.Text()); ok {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// parseReflogLine parses "<old> <new> <name> <<email>> <unix time> <tz>\t<message>".
+func parseReflogLine(line string) (reflogEntry, bool) {
+	header, message, _ := strings.Cut(line, "\t")
+	fields := strings.Fields(header)
+	if len(fields) < 4 {
+		return reflogEntry{}, false
+	}
+
+	e := reflogEntry{oldHash: fields[0], newHash: fields[1], message: message}
+	seconds, err := strconv.ParseInt(fields[len(fields)-2], 10, 64)
+	if err == nil {
+		e.when = time.Unix(seconds, 0)
+	}
+	e.who = strings.Join(fields[2:len(fields)-2], " ")
+	return e, true
+}
+
+// describeReflogEntry turns a reflog message such as
+// "checkout: moving from main to feature" into a readable sentence.
+func describeReflogEntry(e reflogEntry) string {
+	action, detail, _ := strings.Cut(e.message, ": ")
+	switch {
+	case detail == "":
+		return action
+	case action == "":
+		return detail
+	case action == "commit":
+		return "Committed: " + detail
+	case action == "commit (initial)":
+		return "Initial commit: " + detail
+	case action == "commit (amend)":
+		return fmt.Sprintf("Amended commit (was %s): %s", shortHash(e.oldHash), detail)
+	case action == "commit (merge)":
+		return "Committed merge: " + detail
+	case action == "reset":
+		return fmt.Sprintf("Reset %s (was %s)", strings.TrimPrefix(detail, "moving "), shortHash(e.oldHash))
+	case action == "checkout":
+		from, to, ok := strings.Cut(strings.TrimPrefix(detail, "moving from "), " to ")
+		if ok {
+			return fmt.Sprintf("Checked out %s (from %s)", to, from)
+		}
+		return "Checked out " + detail
+	case strings.HasPrefix(action, "rebase") && strings.Contains(action, "(start)"):
+		return "Started rebase: " + strings.TrimPrefix(detail, "checkout ")
+	case strings.HasPrefix(action, "rebase") && strings.Contains(action, "(finish)"):
+		branch, onto, ok := strings.Cut(strings.TrimPrefix(detail, "returning to "), " onto ")
+		branch = strings.TrimPrefix(branch, "refs/heads/")
+		if ok {
+			return fmt.Sprintf("Finished rebase of %s onto %s", branch, shortHash(onto))
+		}
+		return "Finished rebase of " + branch
+	case strings.HasPrefix(action, "rebase") && strings.Contains(action, "(abort)"):
+		return "Aborted rebase: " + detail
+	case strings.HasPrefix(action, "rebase"):
+		return "Rebase replayed: " + detail
+	case strings.HasPrefix(action, "merge "):
+		return fmt.Sprintf("Merged %s (%s)", strings.TrimPrefix(action, "merge "), strings.ToLower(detail))
+	case strings.HasPrefix(action, "pull"):
+		return "Pulled: " + detail
+	case action == "cherry-pick":
+		return "Cherry-picked: " + detail
+	case action == "revert":
+		return "Reverted: " + detail
+	case action == "branch":
+		return "Branch " + strings.ToLower(detail[:1]) + detail[1:]
+	case action == "clone":
+		return "Cloned " + detail
+	}
+	return fmt.Sprintf("%s: %s", strings.ToUpper(action[:1])+action[1:], detail)
+}
+
+func printRecoverCommands(ref string, n int, e reflogEntry) {
+	hash := shortHash(e.newHash)
+	fmt.Printf("- %s@{%d} is %s, %s:\n", ref, n, hash, timeAgo(e.when))
+	fmt.Printf("  %s\n", describeReflogEntry(e))
+	fmt.Print("\n")
+	fmt.Println("Keep a branch pointing at it so it cannot be lost again:")
+	fmt.Printf("$ git branch recovered-%s %s\n", hash, hash)
+	fmt.Print("\n")
+	fmt.Println("Or move the current branch back to it:")
+	fmt.Printf("$ git reset --hard %s\n", hash)
+	if t, args, ok := topicForCommandLine("git reset --hard " + hash); ok {
+		for _, r := range matchRisks(t, args) {
+			if r.level == riskDestroysData {
+				fmt.Print("\n")
+				printRiskWarning(r, "")
+			}
+		}
+	}
+	fmt.Print("\n")
+	fmt.Printf("See: %s\n", gitAdvancedTopic("reflog"))
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// timeAgo formats t relative to now, e.g. "3 hours ago".
+func timeAgo(t time.Time) string {
+	if t.IsZero() {
+		return "at an unknown time"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute") + " ago"
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour") + " ago"
+	case d < 30*24*time.Hour:
+		return plural(int(d.Hours()/24), "day") + " ago"
+	case d < 365*24*time.Hour:
+		return plural(int(d.Hours()/24/30), "month") + " ago"
+	}
+	return plural(int(d.Hours()/24/365), "year") + " ago"
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
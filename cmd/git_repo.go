@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitRepo locates the parts of a repository on disk. In a linked worktree
+// dir holds the per-worktree files (HEAD, logs/HEAD) while commonDir holds
+// everything shared (objects, refs, config).
+type gitRepo struct {
+	workTree  string
+	dir       string
+	commonDir string
+	packs     []*packIndex // loaded on first use
+}
+
+var errNotGitRepo = errors.New("not inside a Git repository")
+
+// openGitRepo finds the repository containing the current directory.
+func openGitRepo() (*gitRepo, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		gitPath := filepath.Join(dir, ".git")
+		info, err := os.Stat(gitPath)
+		if err == nil {
+			if info.IsDir() {
+				return newGitRepo(dir, gitPath)
+			}
+			gitDir, err := readGitFile(gitPath)
+			if err != nil {
+				return nil, err
+			}
+			return newGitRepo(dir, gitDir)
+		}
+		if filepath.Dir(dir) == dir {
+			return nil, errNotGitRepo
+		}
+	}
+}
+
+func newGitRepo(workTree, dir string) (*gitRepo, error) {
+	r := &gitRepo{workTree: workTree, dir: dir, commonDir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, "commondir"))
+	if err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(dir, common)
+		}
+		r.commonDir = common
+	}
+	return r, nil
+}
+
+// readGitFile resolves a ".git" file, as used by worktrees and submodules,
+// to the directory it points at.
+func readGitFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("%s is not a valid .git file", path)
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	return target, nil
+}
+
+// path returns the location of a file inside the repository, looking in the
+// per-worktree directory for HEAD-like files and in the common directory
+// for everything else.
+func (r *gitRepo) path(name string) string {
+	switch {
+	case name == "HEAD", name == "ORIG_HEAD", name == "MERGE_HEAD", name == "logs/HEAD",
+		strings.HasPrefix(name, "rebase-"), name == "CHERRY_PICK_HEAD", name == "REVERT_HEAD",
+		name == "REBASE_HEAD", name == "MERGE_MSG":
+		return filepath.Join(r.dir, name)
+	}
+	return filepath.Join(r.commonDir, name)
+}
+
+// relPath shortens a path inside the repository to start at .git, e.g.
+// .git/objects/pack/pack-1234.pack.
+func (r *gitRepo) relPath(path string) string {
+	if rel, err := filepath.Rel(r.commonDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(filepath.Join(".git", rel))
+	}
+	return path
+}
+
+// currentBranch returns the branch HEAD points to, or "" when detached.
+func (r *gitRepo) currentBranch() string {
+	data, err := os.ReadFile(r.path("HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: refs/heads/")
+	if !ok {
+		return ""
+	}
+	return ref
+}
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// readRef resolves a full ref name such as HEAD or refs/heads/main to the
+// object it points to, following symbolic refs.
+func (r *gitRepo) readRef(name string) (string, bool) {
+	for depth := 0; depth < 10; depth++ {
+		data, err := os.ReadFile(r.path(name))
+		if err == nil {
+			value := strings.TrimSpace(string(data))
+			if target, ok := strings.CutPrefix(value, "ref: "); ok {
+				name = target
+				continue
+			}
+			return value, len(value) == 40 && hexPattern.MatchString(value)
+		}
+		hash, ok := r.packedRefs()[name]
+		return hash, ok
+	}
+	return "", false
+}
+
+// packedRefs reads .git/packed-refs, where git pack-refs and git clone
+// store refs instead of one file per ref.
+func (r *gitRepo) packedRefs() map[string]string {
+	refs := map[string]string{}
+	f, err := os.Open(r.path("packed-refs"))
+	if err != nil {
+		return refs
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "^") {
+			continue
+		}
+		if hash, name, ok := strings.Cut(line, " "); ok {
+			refs[name] = hash
+		}
+	}
+	return refs
+}
+
+// refs returns every ref under refs/ with the object it points to. Loose
+// ref files take precedence over packed-refs.
+func (r *gitRepo) refs() map[string]string {
+	refs := r.packedRefs()
+	root := r.path("refs")
+	filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(r.commonDir, p)
+		name := filepath.ToSlash(rel)
+		if hash, ok := r.readRef(name); ok {
+			refs[name] = hash
+		}
+		return nil
+	})
+	return refs
+}
+
+// refsPointingAt returns the short names of the branches and tags that
+// point at hash, as git log --decorate shows them.
+func (r *gitRepo) refsPointingAt(hash string) []string {
+	var head, names []string
+	if current, ok := r.readRef("HEAD"); ok && current == hash {
+		if branch := r.currentBranch(); branch != "" {
+			head = append(head, "HEAD -> "+branch)
+		} else {
+			head = append(head, "HEAD")
+		}
+	}
+	for name, target := range r.refs() {
+		if target != hash {
+			if obj, err := r.readObject(target); err != nil || obj.kind != "tag" || parseGitTag(obj).object != hash {
+				continue
+			}
+		}
+		short := shortRefName(name)
+		if len(head) > 0 && head[0] == "HEAD -> "+short {
+			continue
+		}
+		names = append(names, short)
+	}
+	sort.Strings(names)
+	return append(head, names...)
+}
+
+func shortRefName(name string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/remotes/"} {
+		if short, ok := strings.CutPrefix(name, prefix); ok {
+			return short
+		}
+	}
+	if tag, ok := strings.CutPrefix(name, "refs/tags/"); ok {
+		return "tag: " + tag
+	}
+	return name
+}
+
+var (
+	hexPattern    = regexp.MustCompile(`^[0-9a-fA-F]{4,40}$`)
+	reflogPattern = regexp.MustCompile(`^(.*)@\{(\d+)\}$`)
+)
+
+// resolveRev resolves a revision such as HEAD~2, main^2, v1.0, a1b2c3d or
+// HEAD@{3} to an object name, the way git rev-parse does for these forms.
+func (r *gitRepo) resolveRev(rev string) (string, error) {
+	base, ops := rev, ""
+	if i := strings.IndexAny(rev, "~^"); i >= 0 {
+		base, ops = rev[:i], rev[i:]
+	}
+
+	hash, err := r.resolveRevBase(base)
+	if err != nil {
+		return "", err
+	}
+
+	for ops != "" {
+		op := ops[0]
+		ops = ops[1:]
+		if op != '~' && op != '^' {
+			// Forms such as HEAD^!, HEAD^@ and HEAD^-1 name ranges, not one commit.
+			return "", fmt.Errorf("unsupported revision syntax '%s'", rev)
+		}
+		if op == '^' && strings.HasPrefix(ops, "{") {
+			end := strings.Index(ops, "}")
+			if end < 0 {
+				return "", fmt.Errorf("'%s' is missing a closing brace", rev)
+			}
+			kind := ops[1:end]
+			ops = ops[end+1:]
+			if kind == "" {
+				kind = "commit"
+			}
+			if hash, err = r.peel(hash, kind); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		digits := 0
+		for digits < len(ops) && ops[digits] >= '0' && ops[digits] <= '9' {
+			digits++
+		}
+		n := 1
+		if digits > 0 {
+			n, _ = strconv.Atoi(ops[:digits])
+			ops = ops[digits:]
+		}
+		if hash, err = r.peel(hash, "commit"); err != nil {
+			return "", err
+		}
+
+		if op == '~' {
+			for i := 0; i < n; i++ {
+				c, err := r.readCommit(hash)
+				if err != nil {
+					return "", err
+				}
+				if len(c.parents) == 0 {
+					return "", fmt.Errorf("'%s' goes back further than the first commit", rev)
+				}
+				hash = c.parents[0]
+			}
+			continue
+		}
+		if n == 0 {
+			continue
+		}
+		c, err := r.readCommit(hash)
+		if err != nil {
+			return "", err
+		}
+		if n > len(c.parents) {
+			return "", fmt.Errorf("'%s': commit %s has %s", rev, shortHash(hash), plural(len(c.parents), "parent"))
+		}
+		hash = c.parents[n-1]
+	}
+	return hash, nil
+}
+
+func (r *gitRepo) resolveRevBase(base string) (string, error) {
+	if base == "" || base == "@" {
+		base = "HEAD"
+	}
+
+	if m := reflogPattern.FindStringSubmatch(base); m != nil {
+		ref := m[1]
+		switch {
+		case ref == "" || ref == "@":
+			ref = "HEAD"
+		case ref != "HEAD" && !strings.HasPrefix(ref, "refs/"):
+			ref = "refs/heads/" + ref
+		}
+		entries, err := readReflog(r.path("logs/" + ref))
+		if err != nil {
+			return "", fmt.Errorf("no reflog for %s", m[1])
+		}
+		n, _ := strconv.Atoi(m[2])
+		if n >= len(entries) {
+			return "", fmt.Errorf("the reflog of %s has only %d entries", ref, len(entries))
+		}
+		return entries[n].newHash, nil
+	}
+
+	for _, name := range []string{base, "refs/" + base, "refs/tags/" + base, "refs/heads/" + base, "refs/remotes/" + base, "refs/remotes/" + base + "/HEAD"} {
+		if hash, ok := r.readRef(name); ok {
+			return hash, nil
+		}
+	}
+
+	if hexPattern.MatchString(base) {
+		found := r.findObjects(base)
+		switch len(found) {
+		case 0:
+		case 1:
+			return found[0], nil
+		default:
+			return "", fmt.Errorf("'%s' is ambiguous: %d objects start with it", base, len(found))
+		}
+	}
+	return "", fmt.Errorf("unknown revision '%s'", base)
+}
+
+// peel follows tags, and commits to their trees, until it reaches an object
+// of the given kind.
+func (r *gitRepo) peel(hash, kind string) (string, error) {
+	for {
+		obj, err := r.readObject(hash)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case obj.kind == kind:
+			return hash, nil
+		case obj.kind == "tag":
+			hash = parseGitTag(obj).object
+		case obj.kind == "commit" && kind == "tree":
+			c, _ := parseGitCommit(obj)
+			hash = c.tree
+		default:
+			return "", fmt.Errorf("%s is a %s, not a %s", shortHash(hash), obj.kind, kind)
+		}
+	}
+}
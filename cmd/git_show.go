@@ -0,0 +1,442 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/spf13/cobra"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var gitShowCmd = &cobra.Command{
+	Use:   "show [rev]",
+	Short: "Summarizes a commit in plain language",
+	Long: `This command reads a commit from the repository in the current directory and
+summarizes it: who wrote it and when, whether it is a merge, which files it
+added, changed or deleted with line counts, and whether it reverts or
+cherry-picks another commit. The revision defaults to HEAD.
+For example:
+
+- explain git show
+- explain git show HEAD~3
+- explain git show v1.2.0`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rev := "HEAD"
+		if len(args) == 1 {
+			rev = args[0]
+		}
+		repo, err := openGitRepo()
+		if err != nil {
+			fmt.Println("Could not read the commit:", err)
+			return
+		}
+		hash, err := repo.resolveRev(rev)
+		if err == nil {
+			hash, err = repo.peel(hash, "commit")
+		}
+		if err != nil {
+			fmt.Println("Could not read the commit:", err)
+			return
+		}
+		c, err := repo.readCommit(hash)
+		if err != nil {
+			fmt.Println("Could not read the commit:", err)
+			return
+		}
+		changes, err := repo.commitChanges(c)
+		if err != nil {
+			fmt.Println("Could not compare the commit with its parent:", err)
+			return
+		}
+		printCommitSummary(repo, c, changes)
+	},
+}
+
+func init() {
+	gitCmd.AddCommand(gitShowCmd)
+}
+
+// fileChange is one file added, modified, deleted or renamed by a commit.
+type fileChange struct {
+	status     byte // A, M, D, R or T (type change)
+	path       string
+	oldPath    string
+	oldHash    string
+	newHash    string
+	oldMode    string
+	newMode    string
+	insertions int
+	deletions  int
+	binary     bool
+}
+
+// commitChanges compares a commit with its first parent, or with an empty
+// tree for the first commit.
+func (r *gitRepo) commitChanges(c gitCommit) ([]fileChange, error) {
+	oldTree := ""
+	if len(c.parents) > 0 {
+		parent, err := r.readCommit(c.parents[0])
+		if err != nil {
+			return nil, err
+		}
+		oldTree = parent.tree
+	}
+	changes, err := r.diffTrees(oldTree, c.tree, "")
+	if err != nil {
+		return nil, err
+	}
+	changes = detectRenames(changes)
+
+	for i := range changes {
+		ch := &changes[i]
+		if ch.status == 'R' && ch.oldHash == ch.newHash {
+			continue
+		}
+		var oldData, newData []byte
+		if ch.oldHash != "" && !isSubmoduleMode(ch.oldMode) {
+			obj, err := r.readObject(ch.oldHash)
+			if err != nil {
+				return nil, err
+			}
+			oldData = obj.data
+		}
+		if ch.newHash != "" && !isSubmoduleMode(ch.newMode) {
+			obj, err := r.readObject(ch.newHash)
+			if err != nil {
+				return nil, err
+			}
+			newData = obj.data
+		}
+		ch.insertions, ch.deletions, ch.binary = lineDiffStat(oldData, newData)
+	}
+	return changes, nil
+}
+
+func isSubmoduleMode(mode string) bool {
+	return mode == "160000"
+}
+
+// diffTrees lists the files that differ between two trees, descending only
+// into subtrees whose hashes differ. An empty hash stands for an empty tree.
+func (r *gitRepo) diffTrees(oldHash, newHash, prefix string) ([]fileChange, error) {
+	if oldHash == newHash {
+		return nil, nil
+	}
+	var oldEntries, newEntries []gitTreeEntry
+	var err error
+	if oldHash != "" {
+		if oldEntries, err = r.readTree(oldHash); err != nil {
+			return nil, err
+		}
+	}
+	if newHash != "" {
+		if newEntries, err = r.readTree(newHash); err != nil {
+			return nil, err
+		}
+	}
+
+	old := map[string]gitTreeEntry{}
+	names := map[string]bool{}
+	for _, e := range oldEntries {
+		old[e.name] = e
+		names[e.name] = true
+	}
+	current := map[string]gitTreeEntry{}
+	for _, e := range newEntries {
+		current[e.name] = e
+		names[e.name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []fileChange
+	for _, name := range sorted {
+		o, hadOld := old[name]
+		n, hasNew := current[name]
+		path := prefix + name
+
+		// A directory on either side is compared as a subtree, so a file
+		// replaced by a directory shows up as a deletion plus additions.
+		if hadOld && o.isDir() || hasNew && n.isDir() {
+			oldTree, newTree := "", ""
+			if hadOld && o.isDir() {
+				oldTree = o.hash
+			} else if hadOld {
+				changes = append(changes, fileChange{status: 'D', path: path, oldHash: o.hash, oldMode: o.mode})
+			}
+			if hasNew && n.isDir() {
+				newTree = n.hash
+			} else if hasNew {
+				changes = append(changes, fileChange{status: 'A', path: path, newHash: n.hash, newMode: n.mode})
+			}
+			sub, err := r.diffTrees(oldTree, newTree, path+"/")
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, sub...)
+			continue
+		}
+
+		switch {
+		case !hadOld:
+			changes = append(changes, fileChange{status: 'A', path: path, newHash: n.hash, newMode: n.mode})
+		case !hasNew:
+			changes = append(changes, fileChange{status: 'D', path: path, oldHash: o.hash, oldMode: o.mode})
+		case o.hash != n.hash || o.mode != n.mode:
+			status := byte('M')
+			if o.mode != n.mode && (o.mode == "120000" || n.mode == "120000" || isSubmoduleMode(o.mode) || isSubmoduleMode(n.mode)) {
+				status = 'T'
+			}
+			changes = append(changes, fileChange{status: status, path: path, oldHash: o.hash, newHash: n.hash, oldMode: o.mode, newMode: n.mode})
+		}
+	}
+	return changes, nil
+}
+
+// detectRenames pairs deleted and added files with identical content.
+func detectRenames(changes []fileChange) []fileChange {
+	deleted := map[string][]int{}
+	for i, ch := range changes {
+		if ch.status == 'D' {
+			deleted[ch.oldHash] = append(deleted[ch.oldHash], i)
+		}
+	}
+	renamed := map[int]bool{}
+	for i, ch := range changes {
+		candidates := deleted[ch.newHash]
+		if ch.status != 'A' || len(candidates) == 0 {
+			continue
+		}
+		d := changes[candidates[0]]
+		deleted[ch.newHash] = candidates[1:]
+		renamed[candidates[0]] = true
+		changes[i].status = 'R'
+		changes[i].oldPath, changes[i].oldHash, changes[i].oldMode = d.path, d.oldHash, d.oldMode
+	}
+
+	var result []fileChange
+	for i, ch := range changes {
+		if !renamed[i] {
+			result = append(result, ch)
+		}
+	}
+	return result
+}
+
+// lineDiffStat counts inserted and deleted lines between two versions of a
+// file, like git diff --numstat.
+func lineDiffStat(a, b []byte) (insertions, deletions int, binary bool) {
+	if isBinary(a) || isBinary(b) {
+		return 0, 0, true
+	}
+	la, lb := splitLines(a), splitLines(b)
+
+	// Common leading and trailing lines do not need the full diff.
+	for len(la) > 0 && len(lb) > 0 && la[0] == lb[0] {
+		la, lb = la[1:], lb[1:]
+	}
+	for len(la) > 0 && len(lb) > 0 && la[len(la)-1] == lb[len(lb)-1] {
+		la, lb = la[:len(la)-1], lb[:len(lb)-1]
+	}
+
+	d := editDistance(la, lb)
+	deletions = (d + len(la) - len(lb)) / 2
+	insertions = (d - len(la) + len(lb)) / 2
+	return insertions, deletions, false
+}
+
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editDistance returns the number of line insertions plus deletions that
+// turn a into b, using Myers' algorithm.
+func editDistance(a, b []string) int {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return 0
+	}
+	v := make([]int, 2*max+2)
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[max+k-1] < v[max+k+1] {
+				x = v[max+k+1]
+			} else {
+				x = v[max+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[max+k] = x
+			if x >= n && y >= m {
+				return d
+			}
+		}
+	}
+	return max
+}
+
+var (
+	revertPattern      = regexp.MustCompile(`This reverts commit ([0-9a-f]{7,40})`)
+	cherryPickPattern  = regexp.MustCompile(`\(cherry picked from commit ([0-9a-f]{7,40})\)`)
+	mergeBranchPattern = regexp.MustCompile(`^Merge (?:remote-tracking )?branch '([^']+)'(?: of (\S+))?(?: into (\S+))?`)
+	mergePRPattern     = regexp.MustCompile(`^Merge pull request #(\d+) from (\S+)`)
+	trailerPattern     = regexp.MustCompile(`(?m)^(Co-authored-by|Signed-off-by|Reviewed-by|Reported-by): (.+)$`)
+)
+
+func printCommitSummary(repo *gitRepo, c gitCommit, changes []fileChange) {
+	decorations := ""
+	if refs := repo.refsPointingAt(c.hash); len(refs) > 0 {
+		decorations = " (" + strings.Join(refs, ", ") + ")"
+	}
+	fmt.Printf("commit %s%s\n", c.hash, decorations)
+	fmt.Printf("  %s\n", c.subject())
+	fmt.Print("\n")
+
+	fmt.Printf("Author:    %s, %s (%s)\n", c.author, timeAgo(c.author.when), c.author.when.Format("2006-01-02 15:04 -0700"))
+	if c.committer.String() != c.author.String() {
+		fmt.Printf("Committer: %s, %s. Someone else applied the change, e.g. through a rebase, cherry-pick or the web interface.\n", c.committer, timeAgo(c.committer.when))
+	} else if c.committer.when.Sub(c.author.when) > time.Minute {
+		fmt.Printf("Committed: %s, later than it was written: the commit was amended, rebased or cherry-picked.\n", timeAgo(c.committer.when))
+	}
+	for _, m := range trailerPattern.FindAllStringSubmatch(c.message, -1) {
+		fmt.Printf("%-10s %s\n", strings.SplitN(m[1], "-", 2)[0]+":", m[1]+" "+m[2])
+	}
+	if c.signed {
+		fmt.Println("Signed:    The commit carries a cryptographic signature; check it with git verify-commit.")
+	}
+
+	var see []topicRef
+	switch len(c.parents) {
+	case 0:
+		fmt.Println("Parents:   none. This is a root commit, the start of the history.")
+	case 1:
+		fmt.Printf("Parents:   %s. A regular commit.\n", shortHash(c.parents[0]))
+	default:
+		var short []string
+		for _, p := range c.parents {
+			short = append(short, shortHash(p))
+		}
+		fmt.Printf("Parents:   %s. A merge commit joining %d lines of history.\n", strings.Join(short, ", "), len(c.parents))
+		subject := c.subject()
+		if m := mergeBranchPattern.FindStringSubmatch(subject); m != nil {
+			into := m[3]
+			if into == "" {
+				into = "the branch that was checked out"
+			}
+			fmt.Printf("           It brought %s into %s.\n", m[1], into)
+		} else if m := mergePRPattern.FindStringSubmatch(subject); m != nil {
+			fmt.Printf("           It merged pull request #%s from %s.\n", m[1], m[2])
+		}
+		fmt.Printf("           The changes below are relative to the first parent, %s, i.e. what the merge brought in.\n", short[0])
+		see = append(see, gitCommandTopic("merge"))
+	}
+
+	if m := revertPattern.FindStringSubmatch(c.message); m != nil {
+		fmt.Print("\n")
+		fmt.Printf("This commit reverts %s", m[1][:min(len(m[1]), 12)])
+		if reverted, err := repo.resolveRev(m[1]); err == nil {
+			if rc, err := repo.readCommit(reverted); err == nil {
+				fmt.Printf(" (\"%s\")", rc.subject())
+			}
+		}
+		fmt.Println(": it applies the opposite of that commit's changes, keeping both in the history.")
+		see = append(see, gitAdvancedTopic("revert"))
+	}
+	if m := cherryPickPattern.FindStringSubmatch(c.message); m != nil {
+		fmt.Print("\n")
+		fmt.Printf("This commit is a cherry-pick of %s, copied with git cherry-pick -x, which records where it came from.\n", m[1][:min(len(m[1]), 12)])
+		see = append(see, gitAdvancedTopic("cherry-pick"))
+	}
+
+	fmt.Print("\n")
+	printFileChanges(changes)
+
+	see = append(see, gitCommandTopic("log"))
+	fmt.Print("\n")
+	for _, t := range see {
+		fmt.Printf("See: %s\n", t)
+	}
+}
+
+var fileChangeKinds = map[byte]string{
+	'A': "added",
+	'M': "modified",
+	'D': "deleted",
+	'R': "renamed",
+	'T': "changed type",
+}
+
+func printFileChanges(changes []fileChange) {
+	if len(changes) == 0 {
+		fmt.Println("The commit changes no files. It was made with --allow-empty, or it is a merge that kept the first parent's content.")
+		return
+	}
+
+	counts := map[byte]int{}
+	var insertions, deletions int
+	for _, ch := range changes {
+		counts[ch.status]++
+		insertions += ch.insertions
+		deletions += ch.deletions
+	}
+	var kinds []string
+	for _, status := range []byte("AMDRT") {
+		if counts[status] > 0 {
+			kinds = append(kinds, fmt.Sprintf("%d %s", counts[status], fileChangeKinds[status]))
+		}
+	}
+	fmt.Printf("%s changed (%s), %d insertions(+), %d deletions(-):\n", plural(len(changes), "file"), strings.Join(kinds, ", "), insertions, deletions)
+
+	for _, ch := range changes {
+		name := ch.path
+		if ch.status == 'R' {
+			name = ch.oldPath + " -> " + ch.path
+		}
+		var stat string
+		switch {
+		case ch.binary:
+			stat = "binary"
+		case ch.status == 'R' && ch.insertions == 0 && ch.deletions == 0:
+			stat = "same content"
+		default:
+			stat = fmt.Sprintf("+%d -%d", ch.insertions, ch.deletions)
+		}
+		note := ""
+		switch {
+		case ch.oldMode == "100644" && ch.newMode == "100755":
+			note = "  now executable"
+		case ch.oldMode == "100755" && ch.newMode == "100644":
+			note = "  no longer executable"
+		case ch.newMode == "120000" || ch.status == 'D' && ch.oldMode == "120000":
+			note = "  symbolic link"
+		case isSubmoduleMode(ch.newMode) || isSubmoduleMode(ch.oldMode):
+			note = "  submodule"
+		}
+		fmt.Printf("  %c  %-50s %s%s\n", ch.status, name, stat, note)
+	}
+}
@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	hookShell   string
+	hookStatus  int
+	hookCommand string
+	hookStderr  string
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Explains failed git, docker and kubectl commands automatically",
+	Long: `This command installs a shell hook that runs after every command. When a git,
+docker or kubectl command exits with an error, the hook looks the error up and prints a
+short explanation with a suggested fix.
+
+To see the error, the hook wraps git, docker and kubectl in shell functions that
+copy their standard error to a temporary file. Their standard error is then a
+pipe instead of the terminal, so they behave as in a script: git no longer shows
+progress meters such as 'Receiving objects: 45%', and colours or prompts that
+depend on a terminal may change. Standard output is not affected.
+
+Disable the hook for the current session with:
+  export EXPLAIN_HOOK=off
+
+For example:
+
+- explain hook install --shell bash
+- explain hook uninstall --shell zsh
+- explain hook script --shell fish`,
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Installs the hook into your shell startup file",
+	Run: func(cmd *cobra.Command, args []string) {
+		shell, ok := hookShellName()
+		if !ok {
+			return
+		}
+		script, err := hookScript(shell)
+		if err != nil {
+			fmt.Println("Could not create the hook:", err)
+			return
+		}
+		path, err := installHook(shell, script)
+		if err != nil {
+			fmt.Println("Could not install the hook:", err)
+			return
+		}
+		fmt.Printf("Installed the %s hook in %s.\n", shell, path)
+		fmt.Println("Open a new shell to activate it. Disable it for a session with: " + hookDisableCommand(shell))
+	},
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Removes the hook from your shell startup file",
+	Run: func(cmd *cobra.Command, args []string) {
+		shell, ok := hookShellName()
+		if !ok {
+			return
+		}
+		if err := uninstallHook(shell); err != nil {
+			fmt.Println("Could not uninstall the hook:", err)
+			return
+		}
+		fmt.Printf("Removed the %s hook. Open a new shell for the change to take effect.\n", shell)
+	},
+}
+
+var hookScriptCmd = &cobra.Command{
+	Use:   "script",
+	Short: "Prints the hook script instead of installing it",
+	Run: func(cmd *cobra.Command, args []string) {
+		shell, ok := hookShellName()
+		if !ok {
+			return
+		}
+		script, err := hookScript(shell)
+		if err != nil {
+			fmt.Println("Could not create the hook:", err)
+			return
+		}
+		fmt.Print(script)
+	},
+}
+
+// hookReportCmd is what the installed hook calls after a command fails.
+var hookReportCmd = &cobra.Command{
+	Use:    "report",
+	Short:  "Explains a failed command (called by the shell hook)",
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, ok := parseCommandLine(hookCommand)
+		if !ok || hookStatus == 0 {
+			return
+		}
+		data, err := os.ReadFile(hookStderr)
+		if err != nil {
+			return
+		}
+
+		matches := matchErrors(tailLines(string(data), 20))
+		if len(matches) == 0 {
+			return
+		}
+		m := matches[0]
+		fmt.Printf("explain: '%s' failed (exit %d): %s\n", strings.Join(c.words, " "), hookStatus, m.pattern.title)
+		fmt.Printf("  %s\n", m.cause)
+		if len(m.fix) > 0 {
+			fmt.Printf("  Try: %s\n", m.fix[0])
+		}
+		if len(m.pattern.topics) > 0 {
+			fmt.Printf("  See: %s\n", m.pattern.topics[0])
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookInstallCmd, hookUninstallCmd, hookScriptCmd, hookReportCmd)
+
+	hookCmd.PersistentFlags().StringVarP(&hookShell, "shell", "s", "", "Shell to use: bash, zsh or fish (default is $SHELL)")
+	hookReportCmd.Flags().IntVar(&hookStatus, "status", 0, "Exit status of the command")
+	hookReportCmd.Flags().StringVar(&hookCommand, "command", "", "The command line that ran")
+	hookReportCmd.Flags().StringVar(&hookStderr, "stderr", "", "File holding the command's standard error")
+}
+
+const hookMarker = "# explain hook"
+
+func hookShellName() (string, bool) {
+	shell := hookShell
+	if shell == "" {
+		shell = filepath.Base(os.Getenv("SHELL"))
+	}
+	switch shell {
+	case "bash", "zsh", "fish":
+		return shell, true
+	}
+	fmt.Printf("Shell '%s' is not supported. Please provide one of the following with --shell:\nbash\nzsh\nfish\n", shell)
+	return "", false
+}
+
+func hookDisableCommand(shell string) string {
+	if shell == "fish" {
+		return "set -gx EXPLAIN_HOOK off"
+	}
+	return "export EXPLAIN_HOOK=off"
+}
+
+// The hook wraps git, docker and kubectl in functions that copy their standard
+// error to a per-shell file, then checks the exit status before the next
+// prompt is drawn. Standard error stops being a terminal, which the Long help
+// of hookCmd points out. The wrapper also records the command line, because
+// the history may be off or skip it (HISTCONTROL=ignorespace).
+const bashHookScript = `# explain hook for bash. Disable for this session with: export EXPLAIN_HOOK=off
+__explain_stderr="${TMPDIR:-/tmp}/explain-hook-$$.err"
+__explain_wrap() {
+  if [ "${EXPLAIN_HOOK:-on}" = off ]; then command "$@"; return; fi
+  printf -v __explain_command '%%q ' "$@"
+  { command "$@" 2>&1 1>&3 3>&- | tee "$__explain_stderr" 1>&2; return "${PIPESTATUS[0]}"; } 3>&1
+}
+git() { __explain_wrap git "$@"; }
+docker() { __explain_wrap docker "$@"; }
+kubectl() { __explain_wrap kubectl "$@"; }
+__explain_precmd() {
+  local status=$?
+  if [ "$status" -ne 0 ] && [ "${EXPLAIN_HOOK:-on}" != off ] && [ -s "$__explain_stderr" ]; then
+    %[1]s hook report --status "$status" --stderr "$__explain_stderr" --command "${__explain_command%% }"
+  fi
+  rm -f "$__explain_stderr"
+  __explain_command=
+  return "$status"
+}
+PROMPT_COMMAND="__explain_precmd${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+`
+
+const zshHookScript = `# explain hook for zsh. Disable for this session with: export EXPLAIN_HOOK=off
+__explain_stderr="${TMPDIR:-/tmp}/explain-hook-$$.err"
+__explain_wrap() {
+  if [[ "${EXPLAIN_HOOK:-on}" == off ]]; then command "$@"; return; fi
+  { command "$@" 2>&1 1>&3 3>&- | tee "$__explain_stderr" 1>&2; return "${pipestatus[1]}"; } 3>&1
+}
+git() { __explain_wrap git "$@"; }
+docker() { __explain_wrap docker "$@"; }
+kubectl() { __explain_wrap kubectl "$@"; }
+__explain_preexec() { __explain_command="$1"; }
+__explain_precmd() {
+  local exit_status=$?
+  if (( exit_status != 0 )) && [[ "${EXPLAIN_HOOK:-on}" != off && -s "$__explain_stderr" ]]; then
+    %[1]s hook report --status "$exit_status" --stderr "$__explain_stderr" --command "$__explain_command"
+  fi
+  rm -f "$__explain_stderr"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook preexec __explain_preexec
+add-zsh-hook precmd __explain_precmd
+`
+
+const fishHookScript = `# explain hook for fish. Disable for this session with: set -gx EXPLAIN_HOOK off
+set -g __explain_stderr (set -q TMPDIR; and echo $TMPDIR; or echo /tmp)/explain-hook-$fish_pid.err
+function __explain_wrap
+    if test "$EXPLAIN_HOOK" = off
+        command $argv
+        return $status
+    end
+    command $argv 2>| tee $__explain_stderr 1>&2
+    return $pipestatus[1]
+end
+function git --wraps git
+    __explain_wrap git $argv
+end
+function docker --wraps docker
+    __explain_wrap docker $argv
+end
+function kubectl --wraps kubectl
+    __explain_wrap kubectl $argv
+end
+function __explain_postexec --on-event fish_postexec
+    set -l exit_status $status
+    if test $exit_status -ne 0; and test "$EXPLAIN_HOOK" != off; and test -s $__explain_stderr
+        %[1]s hook report --status $exit_status --stderr $__explain_stderr --command "$argv[1]"
+    end
+    rm -f $__explain_stderr
+end
+`
+
+// hookScript returns the hook for shell, calling this executable by its
+// absolute path so the hook works even if explain is not on $PATH.
+func hookScript(shell string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	quoted := "'" + strings.ReplaceAll(exe, "'", `'\''`) + "'"
+
+	switch shell {
+	case "zsh":
+		return fmt.Sprintf(zshHookScript, quoted), nil
+	case "fish":
+		return fmt.Sprintf(fishHookScript, quoted), nil
+	}
+	return fmt.Sprintf(bashHookScript, quoted), nil
+}
+
+// hookPaths returns where the hook script is written and the startup file
+// that loads it. Fish loads everything in conf.d, so it needs no startup
+// file edit.
+func hookPaths(shell string) (script, rc string, err error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return "", "", err
+	}
+
+	switch shell {
+	case "fish":
+		// Fish reads $XDG_CONFIG_HOME or ~/.config on every platform, also on
+		// macOS where os.UserConfigDir is ~/Library/Application Support.
+		fishConfig := os.Getenv("XDG_CONFIG_HOME")
+		if fishConfig == "" {
+			fishConfig = filepath.Join(home, ".config")
+		}
+		return filepath.Join(fishConfig, "fish", "conf.d", "explain.fish"), "", nil
+	case "zsh":
+		dir := os.Getenv("ZDOTDIR")
+		if dir == "" {
+			dir = home
+		}
+		return filepath.Join(config, "explain", "hook.zsh"), filepath.Join(dir, ".zshrc"), nil
+	}
+	return filepath.Join(config, "explain", "hook.bash"), filepath.Join(home, ".bashrc"), nil
+}
+
+func installHook(shell, script string) (string, error) {
+	scriptPath, rc, err := hookPaths(shell)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(scriptPath), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(scriptPath, []byte(script), 0o644); err != nil {
+		return "", err
+	}
+	if rc == "" {
+		return scriptPath, nil
+	}
+
+	data, err := os.ReadFile(rc)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if strings.Contains(string(data), hookMarker) {
+		return rc, nil
+	}
+	f, err := os.OpenFile(rc, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "\n[ -f '%[1]s' ] && . '%[1]s' %[2]s\n", scriptPath, hookMarker)
+	return rc, err
+}
+
+func uninstallHook(shell string) error {
+	scriptPath, rc, err := hookPaths(shell)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(scriptPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if rc == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(rc)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var kept []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.Contains(line, hookMarker) {
+			kept = append(kept, line)
+		}
+	}
+	return os.WriteFile(rc, []byte(strings.Join(kept, "\n")), 0o644)
+}
+
+// tailLines returns the last n lines of text.
+func tailLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+var howCmd = &cobra.Command{
+	Use:   "how [goal]",
+	Short: "Shows the commands needed to reach a goal",
+	Long: `This command maps everyday goals to the sequence of commands that achieve them.
+For example:
+
+- explain how undo my last commit but keep changes
+- explain how remove a file from history
+- explain how copy a file out of a container`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			listRecipes()
+			return
+		}
+
+		goal := strings.Join(args, " ")
+		r, ok := findRecipe(goal)
+		if !ok {
+			fmt.Printf("No recipe found for '%s'. Run 'explain how' to see the available goals.\n", goal)
+			return
+		}
+		printRecipe(r)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(howCmd)
+}
+
+// recipe is an ordered list of commands that together accomplish a goal.
+type recipe struct {
+	id       string
+	goal     string
+	keywords []string
+	steps    []recipeStep
+}
+
+type recipeStep struct {
+	command     string
+	explanation string
+	topic       *topicRef
+}
+
+func step(command, explanation string, topic topicRef) recipeStep {
+	return recipeStep{command: command, explanation: explanation, topic: &topic}
+}
+
+func plainStep(command, explanation string) recipeStep {
+	return recipeStep{command: command, explanation: explanation}
+}
+
+var recipes = []recipe{
+	{
+		id:       "undo-last-commit",
+		goal:     "Undo my last commit but keep the changes",
+		keywords: []string{"undo", "uncommit", "last", "commit", "keep", "changes"},
+		steps: []recipeStep{
+			step("git reset --soft HEAD~1", "Move the branch back one commit; the changes stay staged.", gitCommandTopic("reset")),
+			step("git status", "Check that the changes are still in the staging area.", gitCommandTopic("status")),
+		},
+	},
+	{
+		id:       "unstage-file",
+		goal:     "Unstage a file I added by mistake",
+		keywords: []string{"unstage", "remove", "staging", "added", "mistake", "file"},
+		steps: []recipeStep{
+			step("git reset HEAD file.txt", "Remove 'file.txt' from the staging area without touching the working copy.", gitCommandTopic("reset")),
+			step("git status", "Confirm the file is listed as modified but not staged.", gitCommandTopic("status")),
+		},
+	},
+	{
+		id:       "discard-local-changes",
+		goal:     "Throw away my local changes",
+		keywords: []string{"discard", "throw", "away", "local", "changes", "edits", "clean", "start", "over"},
+		steps: []recipeStep{
+			step("git status", "See which files would lose their changes.", gitCommandTopic("status")),
+			step("git reset --hard HEAD", "Reset the staging area and working directory to the last commit.", gitCommandTopic("reset")),
+		},
+	},
+	{
+		id:       "amend-last-commit",
+		goal:     "Fix the message or content of my last commit",
+		keywords: []string{"amend", "fix", "change", "message", "last", "commit", "typo"},
+		steps: []recipeStep{
+			step("git add file.txt", "Stage any forgotten changes (skip if only the message is wrong).", gitCommandTopic("add")),
+			step("git commit --amend -m 'Better message'", "Replace the last commit with a new one containing the staged changes and message.", gitCommandTopic("commit")),
+		},
+	},
+	{
+		id:       "remove-file-from-history",
+		goal:     "Remove a file from the entire history",
+		keywords: []string{"remove", "delete", "file", "history", "secret", "password", "purge"},
+		steps: []recipeStep{
+			step("git filter-branch --index-filter 'git rm --cached --ignore-unmatch secrets.txt' -- --all", "Rewrite every commit on every branch without 'secrets.txt'.", gitAdvancedTopic("filter-branch")),
+			step("git push origin --force --all", "Replace the remote branches with the rewritten history.", gitCommandTopic("push")),
+			step("git push origin --force --tags", "Replace the remote tags as well.", gitCommandTopic("tag")),
+		},
+	},
+	{
+		id:       "undo-pushed-commit",
+		goal:     "Undo a commit that was already pushed",
+		keywords: []string{"undo", "revert", "pushed", "commit", "shared", "public"},
+		steps: []recipeStep{
+			step("git log", "Find the hash of the commit to undo.", gitCommandTopic("log")),
+			step("git revert abc123", "Create a new commit that reverses the changes of abc123.", gitAdvancedTopic("revert")),
+			step("git push origin main", "Share the reverting commit without rewriting history.", gitCommandTopic("push")),
+		},
+	},
+	{
+		id:       "recover-lost-commit",
+		goal:     "Recover a commit or branch I lost",
+		keywords: []string{"recover", "lost", "deleted", "branch", "commit", "restore", "find"},
+		steps: []recipeStep{
+			step("git reflog", "List where HEAD has pointed recently, including commits no branch references.", gitAdvancedTopic("reflog")),
+			step("git branch rescued abc123", "Create a branch at the lost commit so it is safe again.", gitCommandTopic("branch")),
+		},
+	},
+	{
+		id:       "save-work-in-progress",
+		goal:     "Put my unfinished work aside and switch branches",
+		keywords: []string{"save", "unfinished", "work", "progress", "switch", "branch", "aside", "temporary"},
+		steps: []recipeStep{
+			step("git stash", "Save uncommitted changes and clean the working directory.", gitAdvancedTopic("stash")),
+			plainStep("git switch other-branch", "Work on another branch."),
+			step("git stash pop", "Come back and reapply the saved changes.", gitAdvancedTopic("stash")),
+		},
+	},
+	{
+		id:       "update-branch-from-main",
+		goal:     "Update my feature branch with the latest main",
+		keywords: []string{"update", "sync", "feature", "branch", "latest", "main", "master", "upstream"},
+		steps: []recipeStep{
+			step("git fetch origin", "Download the latest commits from the remote.", gitCommandTopic("fetch")),
+			step("git rebase origin/main", "Replay your commits on top of the updated main.", gitAdvancedTopic("rebase")),
+			step("git push --force-with-lease", "Update your remote branch, refusing if someone else pushed to it.", gitCommandTopic("push")),
+		},
+	},
+	{
+		id:       "copy-commit-from-branch",
+		goal:     "Copy a single commit from another branch",
+		keywords: []string{"copy", "single", "commit", "another", "branch", "apply", "pick"},
+		steps: []recipeStep{
+			step("git log other-branch", "Find the hash of the commit you want.", gitCommandTopic("log")),
+			step("git cherry-pick -x abc123", "Apply that commit on the current branch, recording where it came from.", gitAdvancedTopic("cherry-pick")),
+		},
+	},
+	{
+		id:       "find-breaking-commit",
+		goal:     "Find the commit that introduced a bug",
+		keywords: []string{"find", "commit", "introduced", "bug", "regression", "broke", "breaking"},
+		steps: []recipeStep{
+			step("git bisect start", "Begin a binary search through history.", gitAdvancedTopic("bisect")),
+			step("git bisect bad", "Mark the current commit as broken.", gitAdvancedTopic("bisect")),
+			step("git bisect good v1.0", "Mark a known good commit; git checks out the midpoint.", gitAdvancedTopic("bisect")),
+			step("git bisect reset", "Return to where you started once the culprit is found.", gitAdvancedTopic("bisect")),
+		},
+	},
+	{
+		id:       "publish-new-repository",
+		goal:     "Put an existing project on a remote repository",
+		keywords: []string{"publish", "new", "existing", "project", "remote", "repository", "github", "upload"},
+		steps: []recipeStep{
+			step("git init", "Create a repository in the project directory.", gitCommandTopic("init")),
+			step("git add .", "Stage every file.", gitCommandTopic("add")),
+			step("git commit -m 'Initial commit'", "Record the first commit.", gitCommandTopic("commit")),
+			step("git remote add origin https://github.com/example/repo.git", "Point 'origin' at the remote repository.", gitCommandTopic("remote")),
+			step("git push -u origin main", "Upload the branch and remember 'origin/main' as its upstream.", gitCommandTopic("push")),
+		},
+	},
+	{
+		id:       "rename-branch",
+		goal:     "Rename a branch locally and on the remote",
+		keywords: []string{"rename", "branch", "name", "remote", "move"},
+		steps: []recipeStep{
+			step("git branch -m old-name new-name", "Rename the local branch.", gitCommandTopic("branch")),
+			step("git push -u origin new-name", "Push the branch under its new name.", gitCommandTopic("push")),
+			step("git push origin --delete old-name", "Delete the old name from the remote.", gitCommandTopic("push")),
+		},
+	},
+	{
+		id:       "copy-file-from-container",
+		goal:     "Copy a file out of a container",
+		keywords: []string{"copy", "file", "out", "container", "extract", "get", "download"},
+		steps: []recipeStep{
+			plainStep("docker ps", "Find the name or ID of the container."),
+			plainStep("docker cp my-container:/app/log.txt ./log.txt", "Copy '/app/log.txt' from the container to the current directory."),
+		},
+	},
+	{
+		id:       "shell-into-container",
+		goal:     "Open a shell inside a running container",
+		keywords: []string{"open", "shell", "inside", "running", "container", "bash", "attach", "enter"},
+		steps: []recipeStep{
+			plainStep("docker ps", "Find the name or ID of the container."),
+			plainStep("docker exec -it my-container sh", "Start an interactive shell in the running container."),
+		},
+	},
+	{
+		id:       "publish-image",
+		goal:     "Build an image and push it to a registry",
+		keywords: []string{"build", "image", "push", "publish", "registry", "upload"},
+		steps: []recipeStep{
+			step("docker build -t my-registry/my-image:1.0 .", "Build the image from the Dockerfile in the current directory.", dockerCommandTopic("build")),
+			step("docker push my-registry/my-image:1.0", "Upload the image to the registry.", dockerCommandTopic("push")),
+		},
+	},
+	{
+		id:       "persist-container-data",
+		goal:     "Keep container data after the container is removed",
+		keywords: []string{"keep", "persist", "data", "container", "removed", "volume", "storage", "database"},
+		steps: []recipeStep{
+			step("docker volume create my-data", "Create a named volume managed by Docker.", dockerAdvancedTopic("volume")),
+			step("docker run -v my-data:/var/lib/data my-image", "Mount the volume where the application writes its data.", dockerCommandTopic("run")),
+		},
+	},
+	{
+		id:       "connect-containers",
+		goal:     "Let two containers talk to each other",
+		keywords: []string{"connect", "containers", "talk", "network", "communicate", "each", "other"},
+		steps: []recipeStep{
+			step("docker network create my-network", "Create a user-defined bridge network.", dockerAdvancedTopic("network")),
+			step("docker run -d --name db --network my-network postgres", "Start the first container on the network.", dockerCommandTopic("run")),
+			step("docker run -d --network my-network my-app", "Start the second one; it reaches the first at the hostname 'db'.", dockerCommandTopic("run")),
+		},
+	},
+	{
+		id:       "run-application-stack",
+		goal:     "Start an application made of several containers",
+		keywords: []string{"start", "application", "stack", "several", "multiple", "containers", "services"},
+		steps: []recipeStep{
+			step("docker compose up -d", "Build and start every service defined in compose.yaml or docker-compose.yml. With the older standalone binary: docker-compose up -d.", dockerAdvancedTopic("compose")),
+			step("docker compose logs", "Follow the output of the services.", dockerAdvancedTopic("compose")),
+			step("docker compose down", "Stop and remove the whole stack when done.", dockerAdvancedTopic("compose")),
+		},
+	},
+	{
+		id:       "deploy-to-kubernetes",
+		goal:     "Deploy a new version to Kubernetes and roll back if it fails",
+		keywords: []string{"deploy", "kubernetes", "kubectl", "release", "version", "rollback", "roll", "back"},
+		steps: []recipeStep{
+			step("kubectl diff -f deploy.yaml", "Preview what the manifest changes in the cluster.", kubectlCommandTopic("apply")),
+			step("kubectl apply -f deploy.yaml", "Send the manifest; the Deployment starts a rollout.", kubectlCommandTopic("apply")),
+			step("kubectl rollout status deployment/web", "Wait until the new pods are ready, or the rollout fails.", kubectlAdvancedTopic("rollout")),
+			step("kubectl rollout undo deployment/web", "If it fails, go back to the previous version.", kubectlAdvancedTopic("rollout")),
+		},
+	},
+	{
+		id:       "reach-pod-locally",
+		goal:     "Reach a service in the cluster from my machine",
+		keywords: []string{"reach", "access", "service", "pod", "cluster", "local", "localhost", "port", "forward", "kubernetes"},
+		steps: []recipeStep{
+			step("kubectl get svc", "Find the service and the port it listens on.", kubectlCommandTopic("get")),
+			step("kubectl port-forward svc/web 8080:80", "Forward localhost:8080 to port 80 of the service while the command runs.", kubectlCommandTopic("port-forward")),
+		},
+	},
+}
+
+// findRecipe returns the recipe whose id matches goal, or else the one that
+// shares the most words with it.
+func findRecipe(goal string) (recipe, bool) {
+	goal = strings.ToLower(strings.TrimSpace(goal))
+	for _, r := range recipes {
+		if r.id == goal {
+			return r, true
+		}
+	}
+
+	best, bestScore := -1, 0
+	for i, r := range recipes {
+		score := 0
+		for _, word := range strings.Fields(goal) {
+			for _, keyword := range r.keywords {
+				if word == keyword {
+					score++
+					break
+				}
+			}
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return recipe{}, false
+	}
+	return recipes[best], true
+}
+
+func printRecipe(r recipe) {
+	fmt.Printf("- %s\n", r.goal)
+	fmt.Print("\n")
+	for i, s := range r.steps {
+		fmt.Printf("%d. $ %s\n", i+1, s.command)
+		fmt.Printf("   %s\n", s.explanation)
+		if s.topic != nil {
+			fmt.Printf("   See: %s\n", s.topic)
+		}
+		if t, args, ok := topicForCommandLine(s.command); ok {
+			for _, r := range matchRisks(t, args) {
+				printRiskWarning(r, "   ")
+			}
+		}
+	}
+}
+
+func listRecipes() {
+	fmt.Println("Available goals:")
+	for _, r := range recipes {
+		fmt.Printf("  %-26s %s\n", r.id, r.goal)
+	}
+	fmt.Print("\n")
+	fmt.Println("Example:")
+	fmt.Println("$ explain how undo my last commit")
+}
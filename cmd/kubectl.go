@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+// Kubectl subcommand
+var kubectlCmd = &cobra.Command{
+	Use:   "kubectl",
+	Short: "Explains something about Kubernetes and kubectl",
+	Long: `This command provides explanations and examples related to kubectl, the
+command-line tool for Kubernetes.
+For example:
+
+- Explaining basic kubectl commands
+- Giving extensive information about advanced Kubernetes features`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			fmt.Printf(`Unknown kubectl subcommand '%s'. Please use one of the following subcommands:
+manifest
+
+Or provide one of the following flags:
+--command
+--advanced
+`, args[0])
+			return
+		}
+
+		command, _ := cmd.Flags().GetString("command")
+		if command != "" {
+			explainKubectlCommand(command)
+			return
+		}
+
+		advanced, _ := cmd.Flags().GetString("advanced")
+		if advanced != "" {
+			explainAdvancedKubectlConcepts(advanced)
+			return
+		}
+
+		fmt.Println("Kubernetes runs containers across a cluster of machines and keeps them in the state you describe. kubectl is its command-line tool.")
+		fmt.Println("- kubectl get: List resources such as pods, deployments and services.")
+		fmt.Println("- kubectl describe: Show the details and recent events of a resource.")
+		fmt.Println("- kubectl apply: Create or update resources from a manifest.")
+		fmt.Println("- kubectl delete: Delete resources.")
+		fmt.Println("- kubectl logs: Print the output of a container.")
+		fmt.Println("- kubectl exec: Run a command in a running container.")
+		fmt.Println("- kubectl port-forward: Reach a pod or service from your machine.")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(kubectlCmd)
+
+	kubectlCmd.Flags().StringVarP(&commandFlag, "command", "c", "", "Specify a kubectl command to explain")
+	kubectlCmd.Flags().StringVarP(&advancedFlag, "advanced", "a", "", "Explain advanced Kubernetes concepts")
+}
+
+func explainKubectlCommand(command string) {
+	switch command {
+	case "get":
+		fmt.Println("The 'kubectl get' command lists resources of one kind.")
+		fmt.Println("Example: kubectl get pods -n my-namespace")
+		fmt.Println("This command lists the pods in the 'my-namespace' namespace with their status, restarts and age.")
+		fmt.Print("\n")
+		fmt.Println("-o wide adds the node and pod IP, -o yaml prints the full object, -l app=web filters by label and -w keeps watching for changes.")
+	case "describe":
+		fmt.Println("The 'kubectl describe' command shows the details of a resource, followed by its recent events.")
+		fmt.Println("Example: kubectl describe pod web-7d4b9c6f5-x2x8q")
+		fmt.Println("This command shows the containers, volumes and conditions of the pod.")
+		fmt.Print("\n")
+		fmt.Println("The Events at the bottom usually say why a pod is Pending or failing: no node with enough resources, an image that cannot be pulled, a failing probe.")
+	case "apply":
+		fmt.Println("The 'kubectl apply' command creates the resources of a manifest, or updates them to match it.")
+		fmt.Println("Example: kubectl apply -f deploy.yaml")
+		fmt.Println("This command sends every resource in deploy.yaml to the cluster. Fields you removed from the file since the last apply are removed from the resource too.")
+		fmt.Print("\n")
+		fmt.Println("To preview the changes first:\n$ kubectl diff -f deploy.yaml")
+		fmt.Println("To have each resource of the manifest explained, and check that the Services select the right pods:\n$ explain kubectl manifest deploy.yaml")
+	case "delete":
+		fmt.Println("The 'kubectl delete' command deletes resources by name, by label or from a manifest.")
+		fmt.Println("Example: kubectl delete -f deploy.yaml")
+		fmt.Println("This command deletes every resource defined in deploy.yaml.")
+		fmt.Print("\n")
+		fmt.Println("Deleting a pod that belongs to a Deployment only replaces it: the Deployment starts a new one. Delete the Deployment to stop it for good.")
+	case "logs":
+		fmt.Println("The 'kubectl logs' command prints what a container wrote to standard output and standard error.")
+		fmt.Println("Example: kubectl logs deploy/web -c app -f")
+		fmt.Println("This command follows the output of the 'app' container in a pod of the 'web' deployment.")
+		fmt.Print("\n")
+		fmt.Println("When a container keeps restarting, --previous shows the output of the run that crashed.")
+	case "exec":
+		fmt.Println("The 'kubectl exec' command runs a command in a running container.")
+		fmt.Println("Example: kubectl exec -it web-7d4b9c6f5-x2x8q -- sh")
+		fmt.Println("This command opens an interactive shell in the pod. Everything after '--' is the command to run.")
+		fmt.Print("\n")
+		fmt.Println("Changes made this way are lost when the pod is replaced; fix the image or the manifest instead.")
+	case "port-forward":
+		fmt.Println("The 'kubectl port-forward' command forwards a port on your machine to a pod, through the API server.")
+		fmt.Println("Example: kubectl port-forward svc/web 8080:80")
+		fmt.Println("This command makes port 80 of a pod behind the 'web' service reachable at localhost:8080 for as long as it runs.")
+		fmt.Print("\n")
+		fmt.Println("Forwarding a service picks one of its pods and stays on it; there is no load balancing. It only listens on localhost unless you pass --address.")
+	// Add more cases for other kubectl commands
+	default:
+		fmt.Printf("Explanation for '%s' kubectl command is not available. Try another kubectl command.\n", command)
+	}
+
+	printRiskWarnings(kubectlCommandTopic(command))
+}
+
+func explainAdvancedKubectlConcepts(command string) {
+	switch command {
+	case "rollout":
+		fmt.Println("- A rollout is how a Deployment moves its pods to a new version: it starts pods from a new ReplicaSet and scales the old one down step by step.")
+		fmt.Println("The old ReplicaSets are kept (10 by default, see revisionHistoryLimit), which is what makes a rollback possible.")
+		fmt.Print("\n")
+		fmt.Println(`Here’s a summary of the different commands associated with rollouts:
+kubectl rollout status            Wait until the rollout has finished, or failed
+kubectl rollout history           List the revisions of a Deployment
+kubectl rollout undo              Roll back to the previous revision, or --to-revision=N
+kubectl rollout restart           Replace every pod, for example to pick up a changed Secret
+kubectl rollout pause             Stop the rollout in the middle; resume continues it`)
+		fmt.Print("\n")
+		fmt.Println("Example:")
+		fmt.Println("Roll the 'web' deployment back to the previous version:")
+		fmt.Println("$ kubectl rollout undo deployment/web")
+	case "contexts":
+		fmt.Println("- A context names a cluster, the user to log in as, and a default namespace. kubectl reads them from ~/.kube/config, or the files listed in $KUBECONFIG.")
+		fmt.Println("Every command goes to the current context, which is shared by all your terminals.")
+		fmt.Print("\n")
+		fmt.Println(`Here’s a summary of the different commands associated with contexts:
+kubectl config get-contexts                      List the contexts; * marks the current one
+kubectl config current-context                   Print the current context
+kubectl config use-context <name>                Switch to another context
+kubectl config set-context --current -n <ns>     Change the default namespace of the current context`)
+		fmt.Print("\n")
+		fmt.Println("Switching the context in one terminal switches it in all of them. For commands that change things, name the context explicitly with --context.")
+		fmt.Print("\n")
+		fmt.Println("Example:")
+		fmt.Println("Switch to the staging cluster:")
+		fmt.Println("$ kubectl config use-context staging")
+	case "rbac":
+		fmt.Println("- RBAC (role-based access control) decides what a user, group or service account may do in the cluster.")
+		fmt.Println("A Role lists verbs (get, list, create, delete, ...) on resources in one namespace; a ClusterRole does the same cluster-wide. A RoleBinding or ClusterRoleBinding grants a role to subjects.")
+		fmt.Print("\n")
+		fmt.Println(`Here’s a summary of the different commands associated with RBAC:
+kubectl auth can-i <verb> <resource>                 Check whether you may do something
+kubectl auth can-i --list                            List everything you may do in the namespace
+kubectl create role <name> --verb=get --resource=pods   Create a Role
+kubectl create rolebinding <name> --role=<role> --serviceaccount=<ns>:<sa>   Grant it`)
+		fmt.Print("\n")
+		fmt.Println("Example:")
+		fmt.Println("Check what the service account of a pod may do:")
+		fmt.Println("$ kubectl auth can-i list secrets --as=system:serviceaccount:default:web")
+	default:
+		fmt.Printf("Explanation for '%s' Kubernetes concept is not available. Try another advanced Kubernetes concept.\n", command)
+	}
+
+	printRiskWarnings(kubectlAdvancedTopic(command))
+}
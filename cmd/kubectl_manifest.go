@@ -0,0 +1,650 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+var kubectlManifestCmd = &cobra.Command{
+	Use:   "manifest <file>",
+	Short: "Explains each resource of a Kubernetes manifest",
+	Long: `This command reads a Kubernetes manifest, which may hold several resources
+separated by ---, and explains what each one does: the pods a Deployment runs,
+the ports a Service forwards, the rules of a Role. It checks how the resources
+fit together: which pods each Service selector matches, whether the Service's
+target port exists, whether a Deployment's selector matches its own pod labels,
+and which ConfigMaps, Secrets and claims are missing from the file.
+For example:
+
+- explain kubectl manifest deploy.yaml
+- helm template my-chart | explain kubectl manifest -`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var data []byte
+		var err error
+		if args[0] == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			fmt.Println("Could not read the manifest:", err)
+			return
+		}
+		resources, err := readManifest(data)
+		if err != nil {
+			fmt.Println("Could not parse the manifest:", err)
+			return
+		}
+		if len(resources) == 0 {
+			fmt.Printf("%s contains no Kubernetes resources.\n", args[0])
+			return
+		}
+		printManifest(args[0], resources)
+	},
+}
+
+func init() {
+	kubectlCmd.AddCommand(kubectlManifestCmd)
+}
+
+// k8sResource is one object of a manifest.
+type k8sResource struct {
+	doc       int // 1-based document number
+	kind      string
+	name      string
+	namespace string // empty for the context's namespace
+	node      *yaml.Node
+}
+
+func (r k8sResource) String() string {
+	return r.kind + "/" + r.name
+}
+
+func (r k8sResource) get(keys ...string) *yaml.Node {
+	return yamlPath(r.node, keys...)
+}
+
+func (r k8sResource) str(keys ...string) string {
+	if n := r.get(keys...); n != nil && n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	return ""
+}
+
+// readManifest returns the resources of every document, with the items of
+// a List expanded.
+func readManifest(data []byte) ([]k8sResource, error) {
+	var resources []k8sResource
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for doc := 1; ; doc++ {
+		var n yaml.Node
+		err := decoder.Decode(&n)
+		if errors.Is(err, io.EOF) {
+			return resources, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", doc, err)
+		}
+		if len(n.Content) == 0 || n.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		root := n.Content[0]
+		items := []*yaml.Node{root}
+		if strings.HasSuffix(scalarValue(yamlPath(root, "kind")), "List") {
+			items = yamlItems(yamlPath(root, "items"))
+		}
+		for _, item := range items {
+			r := k8sResource{doc: doc, node: item}
+			r.kind, r.name, r.namespace = r.str("kind"), r.str("metadata", "name"), r.str("metadata", "namespace")
+			if r.kind != "" {
+				resources = append(resources, r)
+			}
+		}
+	}
+}
+
+// podTemplate returns the pod template of a workload, or the pod itself.
+func (r k8sResource) podTemplate() *yaml.Node {
+	switch r.kind {
+	case "Pod":
+		return r.node
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+		return r.get("spec", "template")
+	case "CronJob":
+		return r.get("spec", "jobTemplate", "spec", "template")
+	}
+	return nil
+}
+
+// yamlStringMap returns a mapping of scalars, such as labels, as a map.
+func yamlStringMap(n *yaml.Node) map[string]string {
+	m := map[string]string{}
+	for _, pair := range yamlPairs(n) {
+		m[pair[0].Value] = pair[1].Value
+	}
+	return m
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "(none)"
+	}
+	var parts []string
+	for k, v := range labels {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// labelMismatches returns the selector terms that labels does not satisfy;
+// an empty result means the selector matches.
+func labelMismatches(selector, labels map[string]string) []string {
+	var keys, mismatches []string
+	for k := range selector {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch v, ok := labels[k]; {
+		case !ok:
+			mismatches = append(mismatches, fmt.Sprintf("%s is missing", k))
+		case v != selector[k]:
+			mismatches = append(mismatches, fmt.Sprintf("%s is %q, not %q", k, v, selector[k]))
+		}
+	}
+	return mismatches
+}
+
+func manifestWarning(format string, args ...any) {
+	fmt.Printf("  !!! %s\n", fmt.Sprintf(format, args...))
+}
+
+func printManifest(file string, resources []k8sResource) {
+	// Empty documents and documents without a kind hold no resources and
+	// are not counted.
+	docs := map[int]bool{}
+	for _, r := range resources {
+		docs[r.doc] = true
+	}
+	name := file
+	if file == "-" {
+		name = "stdin"
+	}
+	fmt.Printf("%s: %s in %s\n", name, plural(len(resources), "resource"), plural(len(docs), "document"))
+	for _, r := range resources {
+		fmt.Print("\n")
+		where := ""
+		if r.namespace != "" {
+			where = " in namespace " + r.namespace
+		}
+		fmt.Printf("%s %s%s   (document %d, line %d)\n", r.kind, r.name, where, r.doc, r.node.Line)
+		switch r.kind {
+		case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job", "CronJob", "Pod":
+			printWorkload(r, resources)
+		case "Service":
+			printService(r, resources)
+		case "Ingress":
+			printIngress(r, resources)
+		case "ConfigMap", "Secret":
+			printConfigData(r)
+		case "PersistentVolumeClaim":
+			inspectField("Requests", firstNonEmpty(r.str("spec", "resources", "requests", "storage"), "?"), "A claim for storage; a PersistentVolume is bound to it, usually created on demand by the storage class.")
+			inspectField("Access modes", firstNonEmpty(strings.Join(yamlStrings(r.get("spec", "accessModes")), ", "), "(none)"), "ReadWriteOnce lets pods on a single node mount it; ReadWriteMany lets pods on any node.")
+			inspectField("Storage class", firstNonEmpty(r.str("spec", "storageClassName"), "(cluster default)"))
+		case "Namespace":
+			fmt.Println("  A namespace groups resources; names only need to be unique within it. Deleting it deletes everything inside.")
+		case "ServiceAccount":
+			fmt.Println("  An identity for pods. Pods that set serviceAccountName to it get its token and the permissions bound to it with RBAC.")
+		case "Role", "ClusterRole":
+			printRole(r)
+		case "RoleBinding", "ClusterRoleBinding":
+			printRoleBinding(r, resources)
+		case "HorizontalPodAutoscaler":
+			printAutoscaler(r, resources)
+		default:
+			fmt.Printf("  Not covered by explain. For the meaning of its fields run: kubectl explain %s.spec\n", strings.ToLower(r.kind))
+		}
+	}
+
+	fmt.Print("\n")
+	fmt.Printf("To preview and apply the manifest:\n$ kubectl diff -f %s\n$ kubectl apply -f %s\n", file, file)
+	fmt.Printf("See: %s\n", kubectlCommandTopic("apply"))
+}
+
+func printWorkload(r k8sResource, resources []k8sResource) {
+	template := r.podTemplate()
+	podLabels := yamlStringMap(yamlPath(template, "metadata", "labels"))
+
+	switch r.kind {
+	case "Deployment":
+		replicas := firstNonEmpty(r.str("spec", "replicas"), "1")
+		strategy := firstNonEmpty(r.str("spec", "strategy", "type"), "RollingUpdate")
+		how := "replaces them step by step when the template changes"
+		if strategy == "Recreate" {
+			how = "stops all of them before starting new ones when the template changes, so there is downtime"
+		}
+		fmt.Printf("  Runs %s of the pod below and %s (%s).\n", plural(atoi(replicas), "replica"), how, strategy)
+	case "StatefulSet":
+		fmt.Printf("  Runs %s with stable names (%s-0, %s-1, ...) that keep their own volumes when rescheduled.\n", plural(atoi(firstNonEmpty(r.str("spec", "replicas"), "1")), "replica"), r.name, r.name)
+		if svc := r.str("spec", "serviceName"); svc != "" && !definesResource(resources, "Service", svc, r.namespace) {
+			manifestWarning("The headless Service %s that gives the pods their DNS names is not in this file.", svc)
+		}
+	case "DaemonSet":
+		fmt.Println("  Runs one copy of the pod below on every node, for example for log collectors or node agents.")
+	case "ReplicaSet":
+		fmt.Println("  Keeps a number of identical pods running. Deployments create ReplicaSets for you; using one directly gives up rollouts.")
+	case "Job":
+		fmt.Printf("  Runs the pod below until it succeeds %s; failed pods are retried up to %s times.\n", plural(atoi(firstNonEmpty(r.str("spec", "completions"), "1")), "time"), firstNonEmpty(r.str("spec", "backoffLimit"), "6"))
+	case "CronJob":
+		fmt.Printf("  Starts a Job on the schedule '%s' (cron syntax, in the controller's time zone unless timeZone is set).\n", r.str("spec", "schedule"))
+	case "Pod":
+		fmt.Println("  A single pod. Nothing recreates it when it dies or its node goes away; a Deployment would.")
+	}
+
+	if selector := r.get("spec", "selector"); selector != nil && r.kind != "Pod" {
+		matchLabels := yamlStringMap(yamlPath(selector, "matchLabels"))
+		inspectField("Selector", formatLabels(matchLabels), "The pods this "+r.kind+" owns. It cannot be changed after creation.")
+		if mismatches := labelMismatches(matchLabels, podLabels); len(mismatches) > 0 {
+			manifestWarning("The selector does not match the pod labels (%s). The API server rejects the %s.", strings.Join(mismatches, "; "), r.kind)
+		}
+		if yamlPath(selector, "matchExpressions") != nil {
+			fmt.Println("  The selector also has matchExpressions, which explain does not evaluate.")
+		}
+	}
+	inspectField("Pod labels", formatLabels(podLabels))
+	if services := selectingServices(r, resources); len(services) > 0 {
+		inspectField("Reached through", strings.Join(services, ", "))
+	}
+
+	spec := yamlPath(template, "spec")
+	// Jobs run to completion; nothing sends them traffic.
+	serves := r.kind != "Job" && r.kind != "CronJob"
+	for _, c := range yamlItems(yamlPath(spec, "containers")) {
+		printContainer(c, serves)
+	}
+	printPodReferences(r, spec, resources)
+}
+
+func printContainer(c *yaml.Node, serves bool) {
+	name := yamlPath(c, "name")
+	image := yamlPath(c, "image")
+	if name == nil || image == nil {
+		return
+	}
+	inspectField("Container "+name.Value, image.Value)
+
+	var ports []string
+	for _, p := range yamlItems(yamlPath(c, "ports")) {
+		port := yamlPath(p, "containerPort")
+		if port == nil {
+			continue
+		}
+		if pname := yamlPath(p, "name"); pname != nil {
+			ports = append(ports, fmt.Sprintf("%s (%s)", port.Value, pname.Value))
+		} else {
+			ports = append(ports, port.Value)
+		}
+	}
+	if len(ports) > 0 {
+		inspectField("", "Ports: "+strings.Join(ports, ", "))
+	}
+
+	if requests := yamlPath(c, "resources", "requests"); requests != nil {
+		inspectField("", fmt.Sprintf("Requests: %s; limits: %s", formatLabels(yamlStringMap(requests)), formatLabels(yamlStringMap(yamlPath(c, "resources", "limits")))))
+	} else {
+		inspectField("", "No resource requests: the scheduler cannot tell how much room it needs, and it is the first to be evicted when a node runs short.")
+	}
+	if serves && yamlPath(c, "readinessProbe") == nil {
+		inspectField("", "No readinessProbe: Services send it traffic as soon as the container starts, before the application is ready.")
+	}
+	if ref, err := parseImageReference(image.Value); err == nil && ref.digest == "" && (ref.defaultTag || ref.tag == "latest") {
+		manifestWarning("The image has no version tag. Nodes may run different versions, and a rollout is not triggered when the image changes.")
+	}
+	for _, env := range yamlItems(yamlPath(c, "env")) {
+		envName, value := yamlPath(env, "name"), yamlPath(env, "value")
+		if envName != nil && value != nil && value.Value != "" && secretVariable.MatchString(envName.Value) {
+			manifestWarning("%s is set in plain text. Anyone who can read the manifest or the pod can read it; use valueFrom.secretKeyRef.", envName.Value)
+		}
+	}
+}
+
+// printPodReferences lists the ConfigMaps, Secrets, claims and service
+// account the pod spec uses, and whether the file defines them.
+func printPodReferences(r k8sResource, spec *yaml.Node, resources []k8sResource) {
+	type reference struct{ kind, name string }
+	var refs []reference
+	seen := map[reference]bool{}
+	use := func(kind string, n *yaml.Node) {
+		if n == nil || n.Value == "" || seen[reference{kind, n.Value}] {
+			return
+		}
+		seen[reference{kind, n.Value}] = true
+		refs = append(refs, reference{kind, n.Value})
+	}
+
+	for _, v := range yamlItems(yamlPath(spec, "volumes")) {
+		use("ConfigMap", yamlPath(v, "configMap", "name"))
+		use("Secret", yamlPath(v, "secret", "secretName"))
+		use("PersistentVolumeClaim", yamlPath(v, "persistentVolumeClaim", "claimName"))
+	}
+	for _, containers := range []string{"initContainers", "containers"} {
+		for _, c := range yamlItems(yamlPath(spec, containers)) {
+			for _, env := range yamlItems(yamlPath(c, "env")) {
+				use("ConfigMap", yamlPath(env, "valueFrom", "configMapKeyRef", "name"))
+				use("Secret", yamlPath(env, "valueFrom", "secretKeyRef", "name"))
+			}
+			for _, from := range yamlItems(yamlPath(c, "envFrom")) {
+				use("ConfigMap", yamlPath(from, "configMapRef", "name"))
+				use("Secret", yamlPath(from, "secretRef", "name"))
+			}
+		}
+	}
+	use("ServiceAccount", yamlPath(spec, "serviceAccountName"))
+	if r.kind == "StatefulSet" {
+		for _, t := range yamlItems(r.get("spec", "volumeClaimTemplates")) {
+			inspectField("Volume claim", scalarValue(yamlPath(t, "metadata", "name")), "Each replica gets its own PersistentVolumeClaim from this template; it is kept when the StatefulSet is deleted.")
+		}
+	}
+
+	for _, ref := range refs {
+		where := "defined in this file"
+		if !definesResource(resources, ref.kind, ref.name, r.namespace) {
+			where = "not in this file; it must already exist in the namespace, or the pod does not start"
+		}
+		inspectField("Uses", fmt.Sprintf("%s/%s (%s)", ref.kind, ref.name, where))
+	}
+}
+
+func definesResource(resources []k8sResource, kind, name, namespace string) bool {
+	for _, r := range resources {
+		if r.kind == kind && r.name == name && r.namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// selectingServices returns the Services of the file whose selector matches
+// the pods of workload.
+func selectingServices(workload k8sResource, resources []k8sResource) []string {
+	labels := yamlStringMap(yamlPath(workload.podTemplate(), "metadata", "labels"))
+	var names []string
+	for _, s := range resources {
+		selector := yamlStringMap(s.get("spec", "selector"))
+		if s.kind == "Service" && s.namespace == workload.namespace && len(selector) > 0 && len(labelMismatches(selector, labels)) == 0 {
+			names = append(names, "Service/"+s.name)
+		}
+	}
+	return names
+}
+
+func printService(r k8sResource, resources []k8sResource) {
+	kind := firstNonEmpty(r.str("spec", "type"), "ClusterIP")
+	switch {
+	case kind == "ExternalName":
+		fmt.Printf("  A DNS alias: %s.%s resolves to %s. No pods or ports are involved.\n", r.name, firstNonEmpty(r.namespace, "<namespace>"), r.str("spec", "externalName"))
+		return
+	case r.str("spec", "clusterIP") == "None":
+		fmt.Println("  A headless Service: no virtual IP, DNS returns the IPs of the pods themselves. StatefulSets use one to give each pod a name.")
+	case kind == "ClusterIP":
+		fmt.Printf("  A stable virtual IP and the DNS name %s.%s.svc inside the cluster, in front of the pods it selects. Not reachable from outside.\n", r.name, firstNonEmpty(r.namespace, "<namespace>"))
+	case kind == "NodePort":
+		fmt.Println("  Like a ClusterIP Service, and also reachable on a port of every node (30000-32767).")
+	case kind == "LoadBalancer":
+		fmt.Println("  Like a NodePort Service, and the cloud provider creates an external load balancer in front of it.")
+	}
+
+	selector := yamlStringMap(r.get("spec", "selector"))
+	if len(selector) == 0 {
+		inspectField("Selector", "(none)", "Without a selector Kubernetes does not manage the endpoints; you create an EndpointSlice yourself.")
+		return
+	}
+	inspectField("Selector", formatLabels(selector), "Traffic goes to every ready pod that has all of these labels.")
+
+	var matched []k8sResource
+	for _, w := range resources {
+		if w.podTemplate() == nil || w.namespace != r.namespace {
+			continue
+		}
+		labels := yamlStringMap(yamlPath(w.podTemplate(), "metadata", "labels"))
+		mismatches := labelMismatches(selector, labels)
+		switch {
+		case len(mismatches) == 0:
+			matched = append(matched, w)
+			inspectField("Matches", fmt.Sprintf("pods of %s (labels %s)", w, formatLabels(labels)))
+		case len(mismatches) == 1 && len(selector) > 1 && len(selectingServices(w, resources)) == 0:
+			// One differing label on pods that no Service reaches is most
+			// likely a typo; anything else is a different workload.
+			manifestWarning("Almost matches the pods of %s, but %s.", w, strings.Join(mismatches, "; "))
+		}
+	}
+	if len(matched) == 0 {
+		manifestWarning("No pods in this file match the selector. Unless pods elsewhere have these labels, the Service has no endpoints and connections fail.")
+	}
+	if len(matched) > 1 {
+		manifestWarning("The selector matches the pods of %s, so traffic is spread over all of them.", plural(len(matched), "workload"))
+	}
+
+	for _, p := range yamlItems(r.get("spec", "ports")) {
+		port := yamlPath(p, "port")
+		if port == nil {
+			continue
+		}
+		target := port
+		if t := yamlPath(p, "targetPort"); t != nil {
+			target = t
+		}
+		protocol := firstNonEmpty(scalarValue(yamlPath(p, "protocol")), "TCP")
+		line := fmt.Sprintf("%s/%s → pod port %s", port.Value, protocol, target.Value)
+		if np := yamlPath(p, "nodePort"); np != nil {
+			line += ", node port " + np.Value
+		}
+		name := "Port"
+		if pname := yamlPath(p, "name"); pname != nil {
+			name = "Port " + pname.Value
+		}
+		inspectField(name, line)
+		for _, w := range matched {
+			if !exposesPort(w, target.Value) {
+				manifestWarning("No container of %s declares port %s. If the application does listen there this still works, but check the number.", w, target.Value)
+			}
+		}
+	}
+}
+
+// yamlItems returns the items of a sequence node, or nil for anything else.
+func yamlItems(n *yaml.Node) []*yaml.Node {
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return n.Content
+}
+
+func scalarValue(n *yaml.Node) string {
+	if n == nil {
+		return ""
+	}
+	return n.Value
+}
+
+// exposesPort reports whether a container of the workload declares port,
+// given as a number or a port name.
+func exposesPort(w k8sResource, port string) bool {
+	for _, c := range yamlItems(yamlPath(w.podTemplate(), "spec", "containers")) {
+		for _, p := range yamlItems(yamlPath(c, "ports")) {
+			if scalarValue(yamlPath(p, "containerPort")) == port || scalarValue(yamlPath(p, "name")) == port {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func printIngress(r k8sResource, resources []k8sResource) {
+	fmt.Println("  Routes HTTP requests from outside the cluster to Services, by host name and path. An ingress controller must be installed to act on it.")
+	if class := r.str("spec", "ingressClassName"); class != "" {
+		inspectField("Class", class, "The ingress controller that handles it.")
+	}
+	for _, tls := range yamlItems(r.get("spec", "tls")) {
+		inspectField("TLS", strings.Join(yamlStrings(yamlPath(tls, "hosts")), ", "), "Certificate from Secret/"+scalarValue(yamlPath(tls, "secretName")))
+	}
+	for _, rule := range yamlItems(r.get("spec", "rules")) {
+		host := firstNonEmpty(scalarValue(yamlPath(rule, "host")), "any host")
+		for _, p := range yamlItems(yamlPath(rule, "http", "paths")) {
+			service := yamlPath(p, "backend", "service")
+			name := scalarValue(yamlPath(service, "name"))
+			port := firstNonEmpty(scalarValue(yamlPath(service, "port", "number")), scalarValue(yamlPath(service, "port", "name")))
+			path := firstNonEmpty(scalarValue(yamlPath(p, "path")), "/")
+			inspectField("Route", fmt.Sprintf("%s%s (%s) → Service/%s port %s", host, path, firstNonEmpty(scalarValue(yamlPath(p, "pathType")), "ImplementationSpecific"), name, port))
+			if !definesResource(resources, "Service", name, r.namespace) {
+				inspectField("", fmt.Sprintf("Service/%s is not in this file; it must exist in the same namespace.", name))
+			} else if !servicePortExists(resources, name, r.namespace, port) {
+				manifestWarning("Service/%s has no port %s; requests on this route fail with 503.", name, port)
+			}
+		}
+	}
+}
+
+func servicePortExists(resources []k8sResource, service, namespace, port string) bool {
+	for _, r := range resources {
+		if r.kind != "Service" || r.name != service || r.namespace != namespace {
+			continue
+		}
+		for _, p := range yamlItems(r.get("spec", "ports")) {
+			if scalarValue(yamlPath(p, "port")) == port || scalarValue(yamlPath(p, "name")) == port {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func printConfigData(r k8sResource) {
+	var keys []string
+	for _, section := range []string{"data", "stringData", "binaryData"} {
+		for _, pair := range yamlPairs(r.get(section)) {
+			keys = append(keys, pair[0].Value)
+		}
+	}
+	sort.Strings(keys)
+	if r.kind == "ConfigMap" {
+		fmt.Println("  Configuration that pods read as environment variables or mount as files, one file per key.")
+	} else {
+		fmt.Printf("  Sensitive data of type %s for pods to read as environment variables or files.\n", firstNonEmpty(r.str("type"), "Opaque"))
+		if r.get("data") != nil {
+			manifestWarning("Values under data are only base64-encoded, not encrypted. Keep this file out of version control, or use a tool such as Sealed Secrets or External Secrets.")
+		}
+	}
+	inspectField("Keys", firstNonEmpty(strings.Join(keys, ", "), "(none)"))
+}
+
+func printRole(r k8sResource) {
+	scope := "in namespace " + firstNonEmpty(r.namespace, "<namespace>")
+	if r.kind == "ClusterRole" {
+		scope = "in every namespace, and on cluster-wide resources, when bound with a ClusterRoleBinding"
+	}
+	fmt.Printf("  Permissions %s. On its own it grants nothing; a binding gives it to users, groups or service accounts.\n", scope)
+	reach := "namespace"
+	if r.kind == "ClusterRole" {
+		reach = "cluster"
+	}
+	for _, rule := range yamlItems(r.get("rules")) {
+		verbs := yamlStrings(yamlPath(rule, "verbs"))
+		resourceNames := yamlStrings(yamlPath(rule, "resources"))
+		if urls := yamlStrings(yamlPath(rule, "nonResourceURLs")); len(urls) > 0 {
+			resourceNames = urls
+		}
+		groups := yamlStrings(yamlPath(rule, "apiGroups"))
+		for i, g := range groups {
+			if g == "" {
+				groups[i] = "core"
+			}
+		}
+		line := fmt.Sprintf("%s on %s", strings.Join(verbs, ", "), strings.Join(resourceNames, ", "))
+		if len(groups) > 0 {
+			line += " (API group " + strings.Join(groups, ", ") + ")"
+		}
+		inspectField("Allows", line)
+		if strings.Contains(line, "*") {
+			manifestWarning("A wildcard grants everything, including resources and verbs added to the cluster later.")
+		}
+		for _, res := range resourceNames {
+			if res == "secrets" && (hasWord(verbs, "get") || hasWord(verbs, "list") || hasWord(verbs, "watch")) {
+				manifestWarning("Reading secrets gives access to every credential in the %s.", reach)
+			}
+		}
+	}
+}
+
+func hasWord(words []string, want string) bool {
+	for _, w := range words {
+		if w == want {
+			return true
+		}
+	}
+	return false
+}
+
+func printRoleBinding(r k8sResource, resources []k8sResource) {
+	roleKind, roleName := r.str("roleRef", "kind"), r.str("roleRef", "name")
+	var subjects []string
+	impersonate := "--as=<user>"
+	for i, s := range yamlItems(r.get("subjects")) {
+		kind, name, ns := scalarValue(yamlPath(s, "kind")), scalarValue(yamlPath(s, "name")), scalarValue(yamlPath(s, "namespace"))
+		subject := kind + "/" + name
+		if ns != "" {
+			subject += " (namespace " + ns + ")"
+		}
+		subjects = append(subjects, subject)
+		if i > 0 {
+			continue
+		}
+		switch kind {
+		case "User":
+			impersonate = "--as=" + name
+		case "Group":
+			impersonate = "--as=<user> --as-group=" + name
+		case "ServiceAccount":
+			impersonate = fmt.Sprintf("--as=system:serviceaccount:%s:%s", firstNonEmpty(ns, r.namespace, "default"), name)
+		}
+	}
+	fmt.Printf("  Grants the permissions of %s/%s to %s.\n", roleKind, roleName, firstNonEmpty(strings.Join(subjects, ", "), "no one"))
+	if r.kind == "RoleBinding" && roleKind == "ClusterRole" {
+		fmt.Println("  Binding a ClusterRole with a RoleBinding limits it to the binding's namespace.")
+	}
+	namespace := r.namespace
+	if roleKind == "ClusterRole" {
+		namespace = ""
+	}
+	if !definesResource(resources, roleKind, roleName, namespace) {
+		inspectField("Role", fmt.Sprintf("%s/%s is not in this file; it must exist in the cluster. Built-in ClusterRoles include view, edit and admin.", roleKind, roleName))
+	}
+	fmt.Printf("  To check the result: kubectl auth can-i --list -n %s %s\n", firstNonEmpty(r.namespace, "<namespace>"), impersonate)
+}
+
+func printAutoscaler(r k8sResource, resources []k8sResource) {
+	target := r.str("spec", "scaleTargetRef", "kind") + "/" + r.str("spec", "scaleTargetRef", "name")
+	fmt.Printf("  Scales %s between %s and %s replicas based on its metrics.\n", target, firstNonEmpty(r.str("spec", "minReplicas"), "1"), r.str("spec", "maxReplicas"))
+	for _, w := range resources {
+		if w.String() != target || w.namespace != r.namespace {
+			continue
+		}
+		if w.get("spec", "replicas") != nil {
+			manifestWarning("%s also sets replicas, so every kubectl apply resets the count the autoscaler chose. Remove replicas from it.", w)
+		}
+		for _, c := range yamlItems(yamlPath(w.podTemplate(), "spec", "containers")) {
+			if yamlPath(c, "resources", "requests") == nil {
+				manifestWarning("Container %s of %s has no resource requests; CPU and memory utilization targets cannot be computed without them.", scalarValue(yamlPath(c, "name")), w)
+			}
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	lastShell string
+	lastFile  string
+)
+
+var lastCmd = &cobra.Command{
+	Use:   "last [n]",
+	Short: "Explains the last git, docker or kubectl command from your shell history",
+	Long: `This command reads your shell history (bash, zsh or fish) and explains the most
+recent git, docker or kubectl command, or the n-th most recent one.
+bash only writes its history file when the shell exits, so commands from the
+current session are missing until you run 'history -a'. To have bash write
+every command right away, add this to ~/.bashrc:
+PROMPT_COMMAND="history -a${PROMPT_COMMAND:+; $PROMPT_COMMAND}"
+For example:
+
+- explain last
+- explain last 3
+- explain last --shell zsh`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		n := 1
+		if len(args) == 1 {
+			var err error
+			n, err = strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				fmt.Printf("'%s' is not a valid position. Use 1 for the most recent command.\n", args[0])
+				return
+			}
+		}
+
+		shell, path, err := historyFile(lastShell, lastFile)
+		if err != nil {
+			fmt.Println("Could not find your shell history:", err)
+			return
+		}
+		history, err := readHistory(shell, path)
+		if err != nil {
+			fmt.Println("Could not read your shell history:", err)
+			return
+		}
+
+		commands := recentCommands(history)
+		if len(commands) < n {
+			fmt.Printf("Found only %d git, docker or kubectl commands in %s.\n", len(commands), path)
+			if shell == "bash" {
+				fmt.Println("bash writes the commands of this session to the file when it exits. Run 'history -a' to write them now, then try again.")
+			}
+			return
+		}
+		printCommandBreakdown(commands[n-1])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lastCmd)
+
+	lastCmd.Flags().StringVarP(&lastShell, "shell", "s", "", "Shell whose history to read: bash, zsh or fish (default is $SHELL)")
+	lastCmd.Flags().StringVarP(&lastFile, "file", "f", "", "Read history from this file instead")
+}
+
+// historyFile returns the shell and the history file to read, following
+// the same variables the shells themselves use.
+func historyFile(shell, path string) (string, string, error) {
+	if shell == "" {
+		shell = filepath.Base(os.Getenv("SHELL"))
+	}
+	if path != "" {
+		return shell, path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+	switch shell {
+	case "bash":
+		if histfile := os.Getenv("HISTFILE"); histfile != "" {
+			return shell, histfile, nil
+		}
+		return shell, filepath.Join(home, ".bash_history"), nil
+	case "zsh":
+		if histfile := os.Getenv("HISTFILE"); histfile != "" {
+			return shell, histfile, nil
+		}
+		dir := os.Getenv("ZDOTDIR")
+		if dir == "" {
+			dir = home
+		}
+		return shell, filepath.Join(dir, ".zsh_history"), nil
+	case "fish":
+		data := os.Getenv("XDG_DATA_HOME")
+		if data == "" {
+			data = filepath.Join(home, ".local", "share")
+		}
+		return shell, filepath.Join(data, "fish", "fish_history"), nil
+	}
+	return "", "", fmt.Errorf("shell '%s' is not supported; use --shell bash, zsh or fish", shell)
+}
+
+// readHistory returns the commands of a history file, oldest first.
+func readHistory(shell, path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch shell {
+	case "zsh":
+		return parseZshHistory(data), nil
+	case "fish":
+		return parseFishHistory(data), nil
+	}
+	return parseBashHistory(data), nil
+}
+
+// parseBashHistory skips the "#<timestamp>" lines bash writes when
+// HISTTIMEFORMAT is set.
+func parseBashHistory(data []byte) []string {
+	var commands []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" || (strings.HasPrefix(line, "#") && isDigits(line[1:])) {
+			continue
+		}
+		commands = append(commands, line)
+	}
+	return commands
+}
+
+// parseZshHistory handles both plain lines and the extended format
+// ": <start>:<duration>;<command>", where multi-line commands continue on
+// lines ending in a backslash.
+func parseZshHistory(data []byte) []string {
+	var commands []string
+	var current strings.Builder
+	for _, line := range strings.Split(string(unmetafyZsh(data)), "\n") {
+		if current.Len() == 0 && strings.HasPrefix(line, ": ") {
+			if _, command, ok := strings.Cut(line, ";"); ok {
+				line = command
+			}
+		}
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(line)
+			current.WriteString("\n")
+			continue
+		}
+		current.WriteString(line)
+		if command := current.String(); strings.TrimSpace(command) != "" {
+			commands = append(commands, command)
+		}
+		current.Reset()
+	}
+	return commands
+}
+
+// unmetafyZsh undoes zsh's history encoding, which stores some bytes as
+// 0x83 followed by the byte XOR 0x20.
+func unmetafyZsh(data []byte) []byte {
+	if bytes.IndexByte(data, 0x83) < 0 {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == 0x83 && i+1 < len(data) {
+			i++
+			out = append(out, data[i]^0x20)
+			continue
+		}
+		out = append(out, data[i])
+	}
+	return out
+}
+
+// parseFishHistory reads the "- cmd: <command>" entries of fish's YAML-like
+// history file.
+func parseFishHistory(data []byte) []string {
+	var commands []string
+	for _, line := range strings.Split(string(data), "\n") {
+		command, ok := strings.CutPrefix(line, "- cmd: ")
+		if !ok {
+			continue
+		}
+		command = strings.NewReplacer(`\\`, `\`, `\n`, "\n").Replace(command)
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+// recentCommands returns the git, docker and kubectl commands in history, newest
+// first. Command lists such as "git add . && git commit" are split and
+// "sudo" is ignored.
+func recentCommands(history []string) []commandLine {
+	var commands []commandLine
+	for i := len(history) - 1; i >= 0; i-- {
+		segments := splitShellList(history[i])
+		for j := len(segments) - 1; j >= 0; j-- {
+			segment := strings.TrimSpace(segments[j])
+			segment = strings.TrimSpace(strings.TrimPrefix(segment, "sudo "))
+			if c, ok := parseCommandLine(segment); ok {
+				commands = append(commands, c)
+			}
+		}
+	}
+	return commands
+}
+
+// splitShellList splits a command line on ";", "&&", "||", "|" and
+// newlines that are outside quotes.
+func splitShellList(line string) []string {
+	var (
+		segments []string
+		start    int
+		quote    byte
+	)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '\\':
+			i++
+		case c == '&' && ((i > 0 && (line[i-1] == '>' || line[i-1] == '<')) || (i+1 < len(line) && line[i+1] == '>')):
+			// Part of a redirection such as 2>&1 or &>file.
+		case c == ';' || c == '\n' || c == '|' || c == '&':
+			segments = append(segments, line[start:i])
+			if i+1 < len(line) && (line[i+1] == '&' || line[i+1] == '|') {
+				i++
+			}
+			start = i + 1
+		}
+	}
+	return append(segments, line[start:])
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
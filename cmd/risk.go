@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+type riskLevel int
+
+const (
+	riskSafe riskLevel = iota
+	riskRewritesHistory
+	riskDestroysData
+)
+
+func (l riskLevel) String() string {
+	switch l {
+	case riskRewritesHistory:
+		return "rewrites history"
+	case riskDestroysData:
+		return "destroys data"
+	default:
+		return "safe"
+	}
+}
+
+// commandRisk describes how a topic, or a particular flag or subcommand of
+// it, can lose work. A risk without triggers or applies applies to the topic
+// as a whole.
+type commandRisk struct {
+	topic    topicRef
+	command  string
+	triggers []string
+	applies  func(args []string) bool // matches arguments that triggers cannot express
+	level    riskLevel
+	warning  string
+	recovery string
+}
+
+var commandRisks = []commandRisk{
+	{
+		topic:    gitCommandTopic("reset"),
+		command:  "git reset <mode> <commit>",
+		triggers: []string{"--soft", "--mixed", "--hard", "--keep", "--merge"},
+		applies:  resetMovesBranch,
+		level:    riskRewritesHistory,
+		warning:  "Moving a branch back drops the later commits from it. Never reset commits that were already pushed.",
+		recovery: "The previous tip is saved in ORIG_HEAD: git reset ORIG_HEAD",
+	},
+	{
+		topic:    gitCommandTopic("reset"),
+		command:  "git reset --hard",
+		triggers: []string{"--hard"},
+		level:    riskDestroysData,
+		warning:  "Uncommitted changes to tracked files are discarded and cannot be recovered.",
+		recovery: "Committed work is still in the reflog: git reflog, then git reset --hard HEAD@{1}",
+	},
+	{
+		topic:    gitCommandTopic("commit"),
+		command:  "git commit --amend",
+		triggers: []string{"--amend"},
+		level:    riskRewritesHistory,
+		warning:  "The last commit is replaced by a new one. Amending a pushed commit forces everyone else to reconcile.",
+		recovery: "The original commit is in the reflog: git reset --soft HEAD@{1}",
+	},
+	{
+		topic:    gitCommandTopic("branch"),
+		command:  "git branch -D",
+		triggers: []string{"-D"},
+		level:    riskDestroysData,
+		warning:  "The branch is deleted even if its commits are not merged anywhere else.",
+		recovery: "git prints the hash of the deleted tip; recreate it with git branch <name> <hash> or find it in git reflog",
+	},
+	{
+		topic:    gitCommandTopic("push"),
+		command:  "git push --force",
+		triggers: []string{"--force", "-f", "--force-with-lease", "--mirror"},
+		level:    riskRewritesHistory,
+		warning:  "The remote branch is overwritten. Commits pushed by others since your last fetch are lost from it.",
+		recovery: "Anyone who fetched the old tip can push it back; the old hash is also printed by push (e.g. '+ abc123...def456')",
+	},
+	{
+		topic:    gitAdvancedTopic("rebase"),
+		command:  "git rebase",
+		level:    riskRewritesHistory,
+		warning:  "Every rebased commit is replaced by a new one. Do not rebase commits that others have already pulled.",
+		recovery: "The branch tip before the rebase is in ORIG_HEAD and the reflog: git reset --hard ORIG_HEAD",
+	},
+	{
+		topic:    gitAdvancedTopic("stash"),
+		command:  "git stash drop",
+		triggers: []string{"drop", "clear"},
+		level:    riskDestroysData,
+		warning:  "Dropped stashes are no longer listed and will eventually be garbage collected.",
+		recovery: "git stash drop prints the stash hash; reapply it with git stash apply <hash> or find it with git fsck --unreachable",
+	},
+	{
+		topic:    gitAdvancedTopic("filter-branch"),
+		command:  "git filter-branch",
+		level:    riskRewritesHistory,
+		warning:  "Every matching commit on the selected branches is rewritten, changing all of their hashes.",
+		recovery: "The original refs are kept under refs/original/: git reset --hard refs/original/refs/heads/main",
+	},
+	{
+		topic:    dockerAdvancedTopic("swarm"),
+		command:  "docker swarm leave --force",
+		triggers: []string{"leave"},
+		level:    riskDestroysData,
+		warning:  "A manager that leaves takes its copy of the swarm state with it. Losing the majority of managers loses the swarm.",
+		recovery: "On a remaining manager run docker swarm init --force-new-cluster, then rejoin the nodes",
+	},
+	{
+		topic:    dockerAdvancedTopic("volume"),
+		command:  "docker volume rm",
+		triggers: []string{"rm", "remove", "prune"},
+		level:    riskDestroysData,
+		warning:  "The volume and every file stored in it are deleted permanently.",
+		recovery: "None; restore the data from a backup",
+	},
+	{
+		topic:    dockerAdvancedTopic("compose"),
+		command:  "docker compose down -v",
+		triggers: []string{"-v", "--volumes"},
+		level:    riskDestroysData,
+		warning:  "Named volumes declared in the Compose file are removed together with the containers.",
+		recovery: "None; restore the data from a backup",
+	},
+	{
+		topic:    kubectlCommandTopic("delete"),
+		command:  "kubectl delete namespace <name>",
+		triggers: []string{"namespace", "ns", "namespaces"},
+		level:    riskDestroysData,
+		warning:  "Every resource in the namespace is deleted with it, including PersistentVolumeClaims and, depending on the reclaim policy, the volumes behind them.",
+		recovery: "None for the data; re-apply the manifests to recreate the resources",
+	},
+	{
+		topic:    kubectlCommandTopic("delete"),
+		command:  "kubectl delete pvc <name>",
+		triggers: []string{"pvc", "persistentvolumeclaim", "persistentvolumeclaims"},
+		level:    riskDestroysData,
+		warning:  "With the usual Delete reclaim policy the volume and its data are deleted together with the claim.",
+		recovery: "None; restore the data from a backup",
+	},
+}
+
+// topicRisks returns every risk documented for a topic.
+func topicRisks(t topicRef) []commandRisk {
+	var risks []commandRisk
+	for _, r := range commandRisks {
+		if r.topic == t {
+			risks = append(risks, r)
+		}
+	}
+	return risks
+}
+
+// matchRisks returns the risks of a topic that apply when it is run with the
+// given arguments.
+func matchRisks(t topicRef, args []string) []commandRisk {
+	var risks []commandRisk
+	for _, r := range topicRisks(t) {
+		if (len(r.triggers) == 0 && r.applies == nil) || hasAnyArg(args, r.triggers) || (r.applies != nil && r.applies(args)) {
+			risks = append(risks, r)
+		}
+	}
+	return risks
+}
+
+// resetMovesBranch reports whether git reset without a mode is given a
+// commit, as in git reset HEAD~2, rather than paths to unstage. A commit
+// followed by paths, as in git reset HEAD~2 file.txt, only resets those paths.
+func resetMovesBranch(args []string) bool {
+	var operands []string
+	for _, a := range args {
+		if a == "--" {
+			return false
+		}
+		if !strings.HasPrefix(a, "-") {
+			operands = append(operands, a)
+		}
+	}
+	if len(operands) != 1 {
+		return false
+	}
+	a := operands[0]
+	return strings.ContainsAny(a, "~^") || strings.Contains(a, "@{") || hexPattern.MatchString(a) ||
+		a == "ORIG_HEAD" || a == "FETCH_HEAD"
+}
+
+func hasAnyArg(args, want []string) bool {
+	for _, a := range args {
+		name, _, _ := strings.Cut(a, "=")
+		for _, w := range want {
+			if name == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// printRiskWarnings prints a warning block for every risk of a topic.
+func printRiskWarnings(t topicRef) {
+	for _, r := range topicRisks(t) {
+		fmt.Print("\n")
+		printRiskWarning(r, "")
+	}
+}
+
+func printRiskWarning(r commandRisk, indent string) {
+	fmt.Printf("%s!!! WARNING (%s): %s\n", indent, r.level, r.command)
+	fmt.Printf("%s!!! %s\n", indent, r.warning)
+	if r.recovery != "" {
+		fmt.Printf("%s    Recovery: %s\n", indent, r.recovery)
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import "fmt"
+
+// topicRef points at an explanation that is reachable through the git,
+// docker or kubectl commands, e.g. "explain git --advanced reflog".
+type topicRef struct {
+	tool     string // "git", "docker" or "kubectl"
+	advanced bool
+	name     string
+}
+
+func gitCommandTopic(name string) topicRef {
+	return topicRef{tool: "git", name: name}
+}
+
+func gitAdvancedTopic(name string) topicRef {
+	return topicRef{tool: "git", advanced: true, name: name}
+}
+
+func dockerCommandTopic(name string) topicRef {
+	return topicRef{tool: "docker", name: name}
+}
+
+func dockerAdvancedTopic(name string) topicRef {
+	return topicRef{tool: "docker", advanced: true, name: name}
+}
+
+func kubectlCommandTopic(name string) topicRef {
+	return topicRef{tool: "kubectl", name: name}
+}
+
+func kubectlAdvancedTopic(name string) topicRef {
+	return topicRef{tool: "kubectl", advanced: true, name: name}
+}
+
+func (t topicRef) String() string {
+	if t.advanced {
+		return fmt.Sprintf("explain %s --advanced %s", t.tool, t.name)
+	}
+	return fmt.Sprintf("explain %s --command %s", t.tool, t.name)
+}
+
+// topic is a short, searchable description of an explanation.
+type topic struct {
+	ref     topicRef
+	summary string
+}
+
+// topics lists every explanation provided by the git, docker and kubectl
+// commands. Keep it in sync with explainGitCommand, explainAdvancedGitConcepts,
+// explainDockerCommand, explainAdvancedDockerConcepts, explainKubectlCommand
+// and explainAdvancedKubectlConcepts.
+var topics = []topic{
+	{gitCommandTopic("init"), "Initialize a new Git repository in the current directory."},
+	{gitCommandTopic("add"), "Add changes to the staging area so they are part of the next commit."},
+	{gitCommandTopic("commit"), "Record staged changes to the repository with a message describing them."},
+	{gitCommandTopic("status"), "Show the status of changes as untracked, modified, or staged in the working directory."},
+	{gitCommandTopic("branch"), "List, create, rename, or delete branches."},
+	{gitCommandTopic("merge"), "Merge changes from a different branch into the current branch."},
+	{gitCommandTopic("pull"), "Fetch from and integrate with another repository or a local branch."},
+	{gitCommandTopic("push"), "Update remote refs along with associated objects; upload commits to a remote repository."},
+	{gitCommandTopic("log"), "Display the commit history: who changed what and when, down to a single line with 'git log -L'."},
+	{gitCommandTopic("clone"), "Clone a repository into a new directory, copying it from a remote URL."},
+	{gitCommandTopic("remote"), "Manage the set of remote repositories, such as adding origin."},
+	{gitCommandTopic("fetch"), "Download changes from a remote repository without merging them."},
+	{gitCommandTopic("reset"), "Unstage changes or move the current branch back to a previous commit."},
+	{gitCommandTopic("tag"), "Create and manage tags that mark releases or versions."},
+	{gitAdvancedTopic("rebase"), "Move or combine a sequence of commits on top of a new base commit for a linear history."},
+	{gitAdvancedTopic("cherry-pick"), "Apply a single commit or a range of commits from one branch to another."},
+	{gitAdvancedTopic("submodule"), "Include an external repository inside a repository at a specific snapshot."},
+	{gitAdvancedTopic("stash"), "Save uncommitted changes to a temporary area to switch branches, then reapply them."},
+	{gitAdvancedTopic("reflog"), "Review where branch tips and HEAD pointed in the past to recover lost commits."},
+	{gitAdvancedTopic("hooks"), "Run scripts automatically before or after Git commands, such as a pre-commit check."},
+	{gitAdvancedTopic("gitflow"), "A branching model with feature, release and develop branches."},
+	{gitAdvancedTopic("revert"), "Create a new commit that undoes the changes made by a previous commit."},
+	{gitAdvancedTopic("filter-branch"), "Rewrite branch history, for example to remove a file from every commit."},
+	{gitAdvancedTopic("bisect"), "Binary search through history to find the commit that introduced a bug."},
+	{dockerCommandTopic("run"), "Run a command in a new container started from an image."},
+	{dockerCommandTopic("build"), "Build an image from a Dockerfile and a build context."},
+	{dockerCommandTopic("push"), "Push an image or a repository to a registry."},
+	{dockerAdvancedTopic("compose"), "Define and run multi-container applications with services, networks and volumes in a YAML file."},
+	{dockerAdvancedTopic("swarm"), "Cluster and orchestrate Docker hosts as a single virtual host with services and stacks."},
+	{dockerAdvancedTopic("network"), "Let containers communicate with each other and the outside world through networks."},
+	{dockerAdvancedTopic("volume"), "Persist data generated by containers and share it between containers."},
+	{kubectlCommandTopic("get"), "List Kubernetes resources such as pods, deployments and services."},
+	{kubectlCommandTopic("describe"), "Show the details and recent events of a Kubernetes resource, e.g. why a pod is pending."},
+	{kubectlCommandTopic("apply"), "Create or update Kubernetes resources from a manifest file."},
+	{kubectlCommandTopic("delete"), "Delete Kubernetes resources by name, label or manifest."},
+	{kubectlCommandTopic("logs"), "Print the output of a container in a pod, including the run that crashed."},
+	{kubectlCommandTopic("exec"), "Run a command or open a shell in a running pod."},
+	{kubectlCommandTopic("port-forward"), "Reach a pod or service of the cluster from a local port."},
+	{kubectlAdvancedTopic("rollout"), "Follow, pause, restart or roll back the rollout of a new Deployment version."},
+	{kubectlAdvancedTopic("contexts"), "Switch between clusters and namespaces with kubeconfig contexts."},
+	{kubectlAdvancedTopic("rbac"), "Control who may do what in the cluster with roles, bindings and service accounts."},
+}
+
+// topicAliases maps subcommands that are explained as part of a broader
+// topic onto that topic's name.
+var topicAliases = map[string]map[string]string{
+	"git": {
+		"flow": "gitflow",
+	},
+	"docker": {
+		"compose": "compose",
+		"node":    "swarm",
+		"service": "swarm",
+		"stack":   "swarm",
+	},
+	"kubectl": {
+		"config": "contexts",
+		"auth":   "rbac",
+	},
+}
@@ -0,0 +1,641 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo <command>",
+	Short: "Explains how to reverse a command you just ran",
+	Long: `This command tells you how to undo a git, docker or kubectl command, or warns you that
+it cannot be undone.
+For example:
+
+- explain undo 'git reset --hard HEAD~2'
+- explain undo 'git commit -m "wip"'
+- explain undo 'docker volume rm my-data'`,
+	// The command to undo carries its own flags, so pass them through.
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 1 && (args[0] == "-h" || args[0] == "--help") {
+			cmd.Help()
+			return
+		}
+		if len(args) == 0 {
+			fmt.Println(`Please provide the command to undo, for example:
+explain undo 'git reset --hard HEAD~2'`)
+			return
+		}
+
+		line := strings.Join(args, " ")
+		c, ok := parseCommandLine(line)
+		if !ok {
+			fmt.Printf("Undo advice for '%s' is not available. Try a git, docker or kubectl command.\n", line)
+			return
+		}
+		rule, ok := findUndoRule(c)
+		if !ok {
+			fmt.Printf("Undo advice for '%s' is not available yet.\n", line)
+			return
+		}
+		printUndoAdvice(c, rule)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+}
+
+// undoRule explains how to reverse a topic, or a particular form of it. The
+// first rule of a topic whose triggers appear on the command line, and whose
+// applies check passes if it has one, wins; a rule without triggers is the
+// fallback for its topic.
+type undoRule struct {
+	topic        topicRef
+	triggers     []string
+	applies      func(c commandLine) bool
+	irreversible bool
+	explanation  string
+	steps        func(c commandLine) []string
+}
+
+var undoRules = []undoRule{
+	{
+		topic:       gitCommandTopic("init"),
+		applies:     initRepoHasHistory,
+		explanation: "The repository already existed, so git init only reinitialized it: commits, branches and configuration were left as they were. There is nothing to undo. Do not delete .git, it holds the whole history.",
+	},
+	{
+		topic:       gitCommandTopic("init"),
+		triggers:    []string{"--bare"},
+		applies:     func(c commandLine) bool { return len(c.operands()) == 0 },
+		explanation: "A bare repository has no .git directory: git init --bare wrote HEAD, config, description and the hooks, info, objects and refs directories straight into the current directory. Remove only those; the directory itself may hold other files.",
+		steps: func(c commandLine) []string {
+			return []string{"rm -rf HEAD config description hooks info objects refs"}
+		},
+	},
+	{
+		topic:       gitCommandTopic("init"),
+		triggers:    []string{"--bare"},
+		explanation: "git init --bare created the repository directory itself; nothing else was touched.",
+		steps: func(c commandLine) []string {
+			return []string{"rm -rf " + c.operand(0, "<directory>")}
+		},
+	},
+	{
+		topic:       gitCommandTopic("init"),
+		explanation: "Initializing only created the .git directory; your files were not touched.",
+		steps: func(c commandLine) []string {
+			return []string{"rm -rf " + initGitDir(c)}
+		},
+	},
+	{
+		topic:       gitCommandTopic("add"),
+		explanation: "The changes were only staged. Unstaging keeps them in the working directory.",
+		steps: func(c commandLine) []string {
+			return []string{"git reset HEAD " + strings.Join(orPlaceholder(c.operands(), "<paths>"), " ")}
+		},
+	},
+	{
+		topic:       gitCommandTopic("commit"),
+		triggers:    []string{"--amend"},
+		explanation: "The commit before the amend is still in the reflog as HEAD@{1}.",
+		steps: func(c commandLine) []string {
+			return []string{"git reset --soft HEAD@{1}"}
+		},
+	},
+	{
+		topic:       gitCommandTopic("commit"),
+		explanation: "Move the branch back one commit; the committed changes stay staged. If the commit was pushed, revert it instead.",
+		steps: func(c commandLine) []string {
+			return []string{"git reset --soft HEAD~1", "# or, if already pushed: git revert HEAD"}
+		},
+	},
+	{
+		topic:       gitCommandTopic("status"),
+		explanation: "'git status' only reads the repository; there is nothing to undo.",
+	},
+	{
+		topic:       gitCommandTopic("log"),
+		explanation: "'git log' only reads the repository; there is nothing to undo.",
+	},
+	{
+		topic:       gitCommandTopic("branch"),
+		triggers:    []string{"-d", "-D", "--delete"},
+		explanation: "Deleting a branch prints the hash of its tip ('Deleted branch x (was abc123)'). Recreate it at that commit; if the message is gone, find the hash in the reflog.",
+		steps: func(c commandLine) []string {
+			return []string{"git reflog", fmt.Sprintf("git branch %s <hash>", c.operand(0, "<branch>"))}
+		},
+	},
+	{
+		topic:       gitCommandTopic("branch"),
+		triggers:    []string{"-m", "-M", "--move"},
+		explanation: "Rename the branch back.",
+		steps: func(c commandLine) []string {
+			ops := c.operands()
+			if len(ops) >= 2 {
+				return []string{fmt.Sprintf("git branch -m %s %s", ops[1], ops[0])}
+			}
+			return []string{"git branch -m <new-name> <old-name>"}
+		},
+	},
+	{
+		topic:       gitCommandTopic("branch"),
+		explanation: "Creating a branch only added a pointer; deleting it loses nothing.",
+		steps: func(c commandLine) []string {
+			return []string{"git branch -d " + c.operand(0, "<branch>")}
+		},
+	},
+	{
+		topic:       gitCommandTopic("merge"),
+		triggers:    []string{"--abort"},
+		explanation: "Aborting threw away the in-progress merge resolution; run the merge again to start over.",
+	},
+	{
+		topic:       gitCommandTopic("merge"),
+		explanation: "While the merge is in progress it can be aborted. Once committed, ORIG_HEAD points to the branch before the merge. If the merge was pushed, revert it against the first parent instead.",
+		steps: func(c commandLine) []string {
+			return []string{
+				"git merge --abort             # merge still in progress",
+				"git reset --hard ORIG_HEAD    # merge committed, not pushed",
+				"git revert -m 1 <merge-commit> # merge already pushed",
+			}
+		},
+	},
+	{
+		topic:       gitCommandTopic("pull"),
+		explanation: "A pull is a fetch followed by a merge (or rebase). ORIG_HEAD points to the branch before it was updated.",
+		steps: func(c commandLine) []string {
+			return []string{"git reset --hard ORIG_HEAD"}
+		},
+	},
+	{
+		topic:       gitCommandTopic("push"),
+		triggers:    []string{"--delete", "-d"},
+		explanation: "Push the deleted branch back from your local copy, or from the hash printed by the push.",
+		steps: func(c commandLine) []string {
+			return []string{fmt.Sprintf("git push %s %s", c.operand(0, "origin"), c.operand(1, "<branch>"))}
+		},
+	},
+	{
+		topic:       gitCommandTopic("push"),
+		triggers:    []string{"--force", "-f", "--force-with-lease"},
+		explanation: "The push printed the old remote tip ('+ abc123...def456 (forced update)'). Force the branch back to it; anyone who fetched before the push also has it in their reflog.",
+		steps: func(c commandLine) []string {
+			return []string{fmt.Sprintf("git push --force-with-lease %s abc123:%s", c.operand(0, "origin"), c.operand(1, "<branch>"))}
+		},
+	},
+	{
+		topic:       gitCommandTopic("push"),
+		explanation: "Other people may already have fetched the commits, so do not rewrite the remote. Add commits that revert the pushed ones and push again.",
+		steps: func(c commandLine) []string {
+			return []string{"git revert <commit>", fmt.Sprintf("git push %s %s", c.operand(0, "origin"), c.operand(1, "<branch>"))}
+		},
+	},
+	{
+		topic:       gitCommandTopic("clone"),
+		explanation: "Cloning only created a new directory; delete it.",
+		steps: func(c commandLine) []string {
+			return []string{"rm -rf " + cloneDirectory(c)}
+		},
+	},
+	{
+		topic:       gitCommandTopic("remote"),
+		triggers:    []string{"add"},
+		explanation: "Remove the remote again.",
+		steps: func(c commandLine) []string {
+			return []string{"git remote remove " + c.operand(1, "<name>")}
+		},
+	},
+	{
+		topic:       gitCommandTopic("remote"),
+		triggers:    []string{"remove", "rm"},
+		explanation: "Removing a remote deletes its configuration and remote-tracking branches. Add it back and fetch to recreate them.",
+		steps: func(c commandLine) []string {
+			name := c.operand(1, "<name>")
+			return []string{fmt.Sprintf("git remote add %s <url>", name), "git fetch " + name}
+		},
+	},
+	{
+		topic:       gitCommandTopic("fetch"),
+		explanation: "Fetching only updates remote-tracking branches; your own branches and files are untouched.",
+	},
+	{
+		topic:        gitCommandTopic("reset"),
+		triggers:     []string{"--hard"},
+		irreversible: true,
+		explanation:  "Commits the branch moved away from are recoverable through ORIG_HEAD and the reflog, but uncommitted changes that were discarded are gone for good.",
+		steps: func(c commandLine) []string {
+			return []string{"git reset --hard ORIG_HEAD", "# or pick the state from: git reflog"}
+		},
+	},
+	{
+		topic:       gitCommandTopic("reset"),
+		triggers:    []string{"--soft", "--mixed"},
+		explanation: "ORIG_HEAD points to where the branch was before the reset.",
+		steps: func(c commandLine) []string {
+			return []string{"git reset --soft ORIG_HEAD"}
+		},
+	},
+	{
+		topic:       gitCommandTopic("reset"),
+		applies:     func(c commandLine) bool { return resetMovesBranch(c.args) },
+		explanation: "Reset to a commit without a mode moved the branch and kept the changes in the working directory. ORIG_HEAD points to where the branch was before.",
+		steps: func(c commandLine) []string {
+			return []string{"git reset ORIG_HEAD"}
+		},
+	},
+	{
+		topic:       gitCommandTopic("reset"),
+		explanation: "Reset with paths only unstaged them; the files in the working directory were not touched. Stage them again.",
+		steps: func(c commandLine) []string {
+			// The paths follow an optional commit, as in git reset HEAD file.txt.
+			paths := c.operands()
+			if len(paths) > 0 && (paths[0] == "HEAD" || resetMovesBranch(paths[:1])) {
+				paths = paths[1:]
+			}
+			return []string{"git add " + strings.Join(orPlaceholder(paths, "<paths>"), " ")}
+		},
+	},
+	{
+		topic:       gitCommandTopic("tag"),
+		triggers:    []string{"-d", "--delete"},
+		explanation: "Deleting a tag prints its hash ('Deleted tag v1.0 (was abc123)'). Recreate the tag from it.",
+		steps: func(c commandLine) []string {
+			return []string{fmt.Sprintf("git tag %s abc123", c.operand(0, "<tag>"))}
+		},
+	},
+	{
+		topic:       gitCommandTopic("tag"),
+		explanation: "Delete the tag, and also from the remote if it was pushed.",
+		steps: func(c commandLine) []string {
+			tag := c.operand(0, "<tag>")
+			return []string{"git tag -d " + tag, "git push origin --delete " + tag}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("rebase"),
+		explanation: "During a rebase you can abort it. After it finished, ORIG_HEAD (and the reflog) point to the branch before the rebase.",
+		steps: func(c commandLine) []string {
+			return []string{"git rebase --abort            # rebase still in progress", "git reset --hard ORIG_HEAD    # rebase finished"}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("cherry-pick"),
+		explanation: "During a cherry-pick you can abort it. Once committed, drop the new commit, or revert it if it was pushed.",
+		steps: func(c commandLine) []string {
+			return []string{"git cherry-pick --abort       # still in progress", "git reset --hard HEAD~1       # committed, not pushed", "git revert HEAD               # already pushed"}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("submodule"),
+		triggers:    []string{"add"},
+		explanation: "Remove the submodule entry, its .gitmodules section and its cloned repository.",
+		steps: func(c commandLine) []string {
+			path := c.operand(2, "<path>")
+			return []string{"git rm " + path, "rm -rf .git/modules/" + path}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("submodule"),
+		explanation: "Submodule updates only change the checked-out commit of the submodule. Check out the commit recorded before, or run 'git submodule update' again.",
+		steps: func(c commandLine) []string {
+			return []string{"git submodule update --init"}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("stash"),
+		triggers:    []string{"drop", "clear"},
+		explanation: "A dropped stash is an unreachable commit until garbage collection. 'git stash drop' printed its hash; otherwise search for it.",
+		steps: func(c commandLine) []string {
+			return []string{"git fsck --unreachable | grep commit", "git stash apply <hash>"}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("stash"),
+		triggers:    []string{"pop", "apply"},
+		explanation: "Discard the reapplied changes. If 'pop' hit a conflict the stash was kept; otherwise store the changes again first.",
+		steps: func(c commandLine) []string {
+			return []string{"git stash", "# or discard them: git reset --hard HEAD"}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("stash"),
+		explanation: "Stashing saved your changes; reapply them.",
+		steps: func(c commandLine) []string {
+			return []string{"git stash pop"}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("reflog"),
+		explanation: "'git reflog' only reads the repository; there is nothing to undo.",
+	},
+	{
+		topic:       gitAdvancedTopic("hooks"),
+		explanation: "Hooks are plain scripts; remove or disable the one you installed.",
+		steps: func(c commandLine) []string {
+			return []string{"rm .git/hooks/<hook-name>"}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("gitflow"),
+		triggers:    []string{"init"},
+		explanation: "'git flow init' stores its settings in the repository configuration.",
+		steps: func(c commandLine) []string {
+			return []string{"git config --remove-section gitflow.branch", "git config --remove-section gitflow.prefix"}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("gitflow"),
+		explanation: "Gitflow commands are made of ordinary merges and branch operations. Undo the merge with ORIG_HEAD or recreate a finished branch from the reflog.",
+		steps: func(c commandLine) []string {
+			return []string{"git reflog", "git reset --hard ORIG_HEAD"}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("revert"),
+		explanation: "A revert is an ordinary commit. Drop it if it was not pushed, otherwise revert the revert.",
+		steps: func(c commandLine) []string {
+			return []string{"git reset --hard HEAD~1    # not pushed", "git revert HEAD            # already pushed"}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("filter-branch"),
+		explanation: "filter-branch keeps the original refs under refs/original/ until you delete them. Once they are gone and garbage collected the old history cannot be restored.",
+		steps: func(c commandLine) []string {
+			return []string{"git reset --hard refs/original/refs/heads/<branch>"}
+		},
+	},
+	{
+		topic:       gitAdvancedTopic("bisect"),
+		explanation: "End the bisect session and return to the commit you started from.",
+		steps: func(c commandLine) []string {
+			return []string{"git bisect reset"}
+		},
+	},
+	{
+		topic:       dockerCommandTopic("run"),
+		explanation: "Stop and remove the container that was created. Named volumes it used are kept.",
+		steps: func(c commandLine) []string {
+			name, _ := c.flag("--name")
+			if name == "" {
+				name = "<container>"
+			}
+			return []string{"docker rm -f " + name}
+		},
+	},
+	{
+		topic:       dockerCommandTopic("build"),
+		explanation: "Remove the image that was built. If the tag pointed to an older image before, that image is now untagged; tag it again by ID.",
+		steps: func(c commandLine) []string {
+			tag, _ := c.flag("-t", "--tag")
+			if tag == "" {
+				tag = "<image>"
+			}
+			return []string{"docker rmi " + tag, "# docker images --filter dangling=true", "# docker tag <old-image-id> " + tag}
+		},
+	},
+	{
+		topic:        dockerCommandTopic("push"),
+		irreversible: true,
+		explanation:  "The Docker CLI cannot delete an image from a registry. Delete the tag through the registry's web interface or API, or push the previous image to the same tag.",
+		steps: func(c commandLine) []string {
+			return []string{fmt.Sprintf("docker push %s   # after retagging the previous image", c.operand(0, "<image>"))}
+		},
+	},
+	{
+		topic:        dockerAdvancedTopic("compose"),
+		triggers:     []string{"-v", "--volumes"},
+		irreversible: true,
+		explanation:  "The named volumes of the project were deleted with their data. Start the stack again to recreate empty volumes and restore the data from a backup.",
+		steps: func(c commandLine) []string {
+			return []string{c.composeBinary() + " up -d"}
+		},
+	},
+	{
+		topic:       dockerAdvancedTopic("compose"),
+		triggers:    []string{"down", "stop", "rm"},
+		explanation: "Start the stack again. Data in named volumes is still there.",
+		steps: func(c commandLine) []string {
+			return []string{c.composeBinary() + " up -d"}
+		},
+	},
+	{
+		topic:       dockerAdvancedTopic("compose"),
+		explanation: "Stop and remove the containers and networks the stack created.",
+		steps: func(c commandLine) []string {
+			return []string{c.composeBinary() + " down"}
+		},
+	},
+	{
+		topic:       dockerAdvancedTopic("swarm"),
+		triggers:    []string{"leave"},
+		explanation: "Join the swarm again with a token from a manager. If the last manager left with --force, the swarm state is lost; recreate it with 'docker swarm init --force-new-cluster' on a former manager.",
+		steps: func(c commandLine) []string {
+			return []string{"docker swarm join-token worker   # on a manager", "docker swarm join --token <token> <manager-ip>:2377"}
+		},
+	},
+	{
+		topic:       dockerAdvancedTopic("swarm"),
+		triggers:    []string{"init"},
+		explanation: "Leave the swarm you just created.",
+		steps: func(c commandLine) []string {
+			return []string{"docker swarm leave --force"}
+		},
+	},
+	{
+		topic:       dockerAdvancedTopic("swarm"),
+		explanation: "Swarm services and stacks are declarative; remove what you created or roll a service back.",
+		steps: func(c commandLine) []string {
+			return []string{"docker service rollback <service>", "docker stack rm <stack>"}
+		},
+	},
+	{
+		topic:       dockerAdvancedTopic("network"),
+		triggers:    []string{"create"},
+		explanation: "Remove the network again once no container uses it.",
+		steps: func(c commandLine) []string {
+			return []string{"docker network rm " + c.operand(1, "<network>")}
+		},
+	},
+	{
+		topic:       dockerAdvancedTopic("network"),
+		triggers:    []string{"rm", "remove", "prune"},
+		explanation: "Networks hold no data; recreate it with the same name and options and reconnect the containers.",
+		steps: func(c commandLine) []string {
+			return []string{"docker network create " + c.operand(1, "<network>"), "docker network connect <network> <container>"}
+		},
+	},
+	{
+		topic:       dockerAdvancedTopic("network"),
+		explanation: "This network command only reads state; there is nothing to undo.",
+	},
+	{
+		topic:       dockerAdvancedTopic("volume"),
+		triggers:    []string{"create"},
+		explanation: "Remove the volume again.",
+		steps: func(c commandLine) []string {
+			return []string{"docker volume rm " + c.operand(1, "<volume>")}
+		},
+	},
+	{
+		topic:        dockerAdvancedTopic("volume"),
+		triggers:     []string{"rm", "remove", "prune"},
+		irreversible: true,
+		explanation:  "The volume and its files were deleted. Only a backup can bring the data back.",
+	},
+	{
+		topic:       dockerAdvancedTopic("volume"),
+		explanation: "This volume command only reads state; there is nothing to undo.",
+	},
+	{
+		topic:       kubectlCommandTopic("apply"),
+		explanation: "Roll a Deployment back to its previous revision, or apply the previous version of the manifest. Resources the manifest created for the first time are removed with delete.",
+		steps: func(c commandLine) []string {
+			file, _ := c.flag("-f", "--filename")
+			if file == "" {
+				file = "<file>"
+			}
+			return []string{"kubectl rollout undo deployment/<name>", "git checkout HEAD~1 -- " + file + " && kubectl apply -f " + file, "kubectl delete -f " + file + "   # to remove everything it created"}
+		},
+	},
+	{
+		topic:        kubectlCommandTopic("delete"),
+		triggers:     []string{"namespace", "ns", "namespaces", "pvc", "persistentvolumeclaim", "persistentvolumeclaims"},
+		irreversible: true,
+		explanation:  "The resources can be recreated from their manifests, but the data in the deleted volumes is gone. Only a backup can bring it back.",
+	},
+	{
+		topic:       kubectlCommandTopic("delete"),
+		explanation: "Recreate the resources from their manifests. Pods owned by a Deployment or StatefulSet were already replaced by their controller.",
+		steps: func(c commandLine) []string {
+			if file, ok := c.flag("-f", "--filename"); ok && file != "" {
+				return []string{"kubectl apply -f " + file}
+			}
+			return []string{"kubectl apply -f <manifest>"}
+		},
+	},
+	{
+		topic:       kubectlAdvancedTopic("rollout"),
+		triggers:    []string{"undo"},
+		explanation: "Undo the rollback by rolling back again: the revision you left is now the previous one.",
+		steps: func(c commandLine) []string {
+			return []string{"kubectl rollout history " + c.operand(1, "deployment/<name>"), "kubectl rollout undo " + c.operand(1, "deployment/<name>")}
+		},
+	},
+	{
+		topic:       kubectlAdvancedTopic("rollout"),
+		triggers:    []string{"pause"},
+		explanation: "Resume the rollout.",
+		steps: func(c commandLine) []string {
+			return []string{"kubectl rollout resume " + c.operand(1, "deployment/<name>")}
+		},
+	},
+	{
+		topic:       kubectlAdvancedTopic("rollout"),
+		triggers:    []string{"restart"},
+		explanation: "A restart only replaced the pods with identical ones; there is nothing to undo. If the new pods fail, roll back.",
+		steps: func(c commandLine) []string {
+			return []string{"kubectl rollout undo " + c.operand(1, "deployment/<name>")}
+		},
+	},
+	{
+		topic:       kubectlAdvancedTopic("contexts"),
+		triggers:    []string{"use-context"},
+		explanation: "Switch back to the context you used before.",
+		steps: func(c commandLine) []string {
+			return []string{"kubectl config get-contexts", "kubectl config use-context <previous-context>"}
+		},
+	},
+}
+
+func findUndoRule(c commandLine) (undoRule, bool) {
+	for _, r := range undoRules {
+		if r.topic != c.topic {
+			continue
+		}
+		if r.applies != nil && !r.applies(c) {
+			continue
+		}
+		if len(r.triggers) == 0 || hasAnyArg(c.args, r.triggers) {
+			return r, true
+		}
+	}
+	return undoRule{}, false
+}
+
+func printUndoAdvice(c commandLine, r undoRule) {
+	fmt.Printf("- To undo '%s':\n", c.line)
+	if r.irreversible {
+		fmt.Println("!!! This command cannot be fully undone.")
+	}
+	fmt.Println(r.explanation)
+	if r.steps != nil {
+		fmt.Print("\n")
+		for _, s := range r.steps(c) {
+			fmt.Println("$ " + s)
+		}
+	}
+	fmt.Print("\n")
+	fmt.Printf("See: %s\n", c.topic)
+}
+
+func orPlaceholder(values []string, placeholder string) []string {
+	if len(values) == 0 {
+		return []string{placeholder}
+	}
+	return values
+}
+
+// initGitDir returns the repository directory "git init" created or
+// reinitialized.
+func initGitDir(c commandLine) string {
+	dir := c.operand(0, ".")
+	if c.hasFlag("--bare") {
+		return dir
+	}
+	if dir == "." {
+		return ".git"
+	}
+	return filepath.Join(dir, ".git")
+}
+
+// initRepoHasHistory reports whether the repository "git init" touched has
+// any refs, i.e. whether it existed with commits before.
+func initRepoHasHistory(c commandLine) bool {
+	dir := initGitDir(c)
+	if _, err := os.Stat(dir); err != nil {
+		return false
+	}
+	repo, err := newGitRepo(filepath.Dir(dir), dir)
+	if err != nil {
+		return false
+	}
+	if len(repo.refs()) > 0 {
+		return true
+	}
+	_, ok := repo.readRef("HEAD")
+	return ok
+}
+
+// cloneDirectory returns the directory "git clone" created: the explicit
+// target or the last path element of the URL without ".git".
+func cloneDirectory(c commandLine) string {
+	ops := c.operands()
+	switch len(ops) {
+	case 0:
+		return "<directory>"
+	case 1:
+		name := strings.TrimSuffix(strings.TrimRight(ops[0], "/"), ".git")
+		if i := strings.LastIndexAny(name, "/:"); i >= 0 {
+			name = name[i+1:]
+		}
+		return name
+	default:
+		return ops[1]
+	}
+}